@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+)
+
+const usage = "usage: aldana gen parser [flags] <grammar file>\n"
+
+var errUsage = errors.New("usage")
+
+// genParser runs "aldana gen parser" with the arguments args.
+func genParser(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("aldana gen parser", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fmt.Fprint(stderr, "\nWrites the Go source of a recursive-descent parser for the grammar.\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+
+	out := fs.String("o", "", "the output file, the standard output when is empty")
+	pkg := fs.String("package", "main", "the package of the source")
+	prefix := fs.String("prefix", "", "the prefix of the declarations: <prefix>Callbacks and New<prefix>Parser")
+	start := fs.String("start", "", "the start production, the first one when is empty")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	src, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	g, err := grammar.Parse(string(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	var b bytes.Buffer
+
+	if err := grammar.Generate(&b, g, &grammar.GenerateOptions{Package: *pkg, Prefix: *prefix, Start: *start}); err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(b.Bytes())
+		return err
+	}
+
+	return os.WriteFile(*out, b.Bytes(), 0o644)
+}
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "gen" || os.Args[2] != "parser" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := genParser(os.Args[3:], os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "aldana: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
@@ -206,7 +206,7 @@ func parseDeclaration(r parser.Reader[*Token], f aldana.ParseRuleFinder[*Token,
 		}
 		nd.Token = r.GetToken()
 		for r.HasTokens() && !IsTokenType(r.GetToken(), RightBrace) {
-			fields, fErr := f("field-statement", r)
+			fields, fErr := f("field-declaration", r)
 
 			if fErr != nil {
 				return nil, fErr
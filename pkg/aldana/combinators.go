@@ -0,0 +1,277 @@
+package aldana
+
+import (
+	"errors"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// isSyntaxFailure returns a boolean that indicates whether err is a syntax failure, from which the combinators can backtrack.
+// Any other error stops the parsing.
+func isSyntaxFailure(err error) bool {
+	return errors.Is(err, parser.ErrInvalidSyntax)
+}
+
+// furthestFailure returns the syntax failure that reached the furthest position between a and b.
+// On equal positions returns b, because it contains all the labels expected there.
+func furthestFailure(a error, b error) error {
+	if a == nil {
+		return b
+	}
+
+	var sa, sb *parser.SyntaxError
+
+	if errors.As(a, &sa) && errors.As(b, &sb) && sa.Position > sb.Position {
+		return a
+	}
+
+	return b
+}
+
+// Token returns a NodeRule that accepts the current token when it satisfies p, and returns the node made by n.
+// Otherwise, fails with a parser.SyntaxError that expects the label l.
+func Token[Tt any, Tn any](p TokenPredicate[Tt], l string, n func(t Tt) Tn) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		t, err := Expect(r, p, l)
+		if err != nil {
+			return *new(Tn), err
+		}
+		return n(t), nil
+	}
+}
+
+// Rule returns a NodeRule that parses the rule named n. Use it for refer to the rules of the parser, even recursively.
+func Rule[Tt any, Tn any](n string) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		return f(n, r)
+	}
+}
+
+// Seq returns a NodeRule that parses all the rules rs, one after the other, and returns the node made by n with their nodes.
+// When any of them fails, the reader is reset to the position before the sequence.
+func Seq[Tt any, Tn any](n func(nds []Tn) Tn, rs ...NodeRule[Tt, Tn]) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		nds := make([]Tn, 0, len(rs))
+
+		for _, pr := range rs {
+			nd, err := pr(r, f)
+			if err != nil {
+				r.Reset(m)
+				return *new(Tn), err
+			}
+			nds = append(nds, nd)
+		}
+
+		return n(nds), nil
+	}
+}
+
+// Alt returns a NodeRule that returns the node of the first rule of rs that succeeds. The reader is reset before trying the next rule.
+// When all of them fail, returns the failure that reached the furthest position, with all the labels expected there.
+// The rules found while trying the alternatives do not recover from their failures.
+func Alt[Tt any, Tn any](rs ...NodeRule[Tt, Tn]) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		tf := trying(f, -1)
+
+		var fail error
+
+		for _, pr := range rs {
+			nd, err := pr(r, tf)
+			if err == nil {
+				return nd, nil
+			}
+
+			r.Reset(m)
+
+			if !isSyntaxFailure(err) {
+				return *new(Tn), err
+			}
+
+			fail = furthestFailure(fail, err)
+		}
+
+		if fail == nil {
+			return *new(Tn), expectedError(r, "alternative")
+		}
+
+		return *new(Tn), fail
+	}
+}
+
+// many parses the rule pr until it fails or does not advance the reader, and returns its nodes.
+// The rules found by pr only recover from the failures after the position where pr is tried.
+func many[Tt any, Tn any](pr NodeRule[Tt, Tn], r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn], nds []Tn) ([]Tn, error) {
+	for {
+		m := r.Mark()
+		nd, err := pr(r, trying(f, m))
+
+		if err != nil {
+			r.Reset(m)
+
+			if !isSyntaxFailure(err) {
+				return nil, err
+			}
+
+			return nds, nil
+		}
+
+		nds = append(nds, nd)
+
+		if r.Position() == m {
+			return nds, nil
+		}
+	}
+}
+
+// Many returns a NodeRule that parses zero or more times the rule pr, and returns the node made by n with their nodes.
+func Many[Tt any, Tn any](pr NodeRule[Tt, Tn], n func(nds []Tn) Tn) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		nds, err := many(pr, r, f, nil)
+		if err != nil {
+			return *new(Tn), err
+		}
+		return n(nds), nil
+	}
+}
+
+// Many1 returns a NodeRule that parses one or more times the rule pr, and returns the node made by n with their nodes.
+func Many1[Tt any, Tn any](pr NodeRule[Tt, Tn], n func(nds []Tn) Tn) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		nd, err := pr(r, f)
+		if err != nil {
+			r.Reset(m)
+			return *new(Tn), err
+		}
+
+		nds, err := many(pr, r, f, []Tn{nd})
+		if err != nil {
+			r.Reset(m)
+			return *new(Tn), err
+		}
+
+		return n(nds), nil
+	}
+}
+
+// Optional returns a NodeRule that returns the node of the rule pr, or the zero value of Tn when it fails.
+// The rules found by pr only recover from the failures after the position where pr is tried.
+func Optional[Tt any, Tn any](pr NodeRule[Tt, Tn]) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		nd, err := pr(r, trying(f, m))
+
+		if err != nil {
+			r.Reset(m)
+
+			if !isSyntaxFailure(err) {
+				return *new(Tn), err
+			}
+
+			return *new(Tn), nil
+		}
+
+		return nd, nil
+	}
+}
+
+// SepBy returns a NodeRule that parses zero or more times the rule pr, separated by the rule sep, and returns the node
+// made by n with the nodes of pr. A trailing separator is not consumed.
+func SepBy[Tt any, Tn any](pr NodeRule[Tt, Tn], sep NodeRule[Tt, Tn], n func(nds []Tn) Tn) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		nd, err := pr(r, trying(f, m))
+
+		if err != nil {
+			r.Reset(m)
+
+			if !isSyntaxFailure(err) {
+				return *new(Tn), err
+			}
+
+			return n(nil), nil
+		}
+
+		next := Seq(func(nds []Tn) Tn {
+			return nds[1]
+		}, sep, pr)
+
+		nds, err := many(next, r, f, []Tn{nd})
+		if err != nil {
+			r.Reset(m)
+			return *new(Tn), err
+		}
+
+		return n(nds), nil
+	}
+}
+
+// Between returns a NodeRule that parses the rules o, pr and c, one after the other, and returns the node of pr.
+func Between[Tt any, Tn any](o NodeRule[Tt, Tn], pr NodeRule[Tt, Tn], c NodeRule[Tt, Tn]) NodeRule[Tt, Tn] {
+	return Seq(func(nds []Tn) Tn {
+		return nds[1]
+	}, o, pr, c)
+}
+
+// Map returns a NodeRule that returns the node made by n with the node of the rule pr.
+func Map[Tt any, Tn any](pr NodeRule[Tt, Tn], n func(nd Tn) Tn) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		nd, err := pr(r, f)
+		if err != nil {
+			return *new(Tn), err
+		}
+		return n(nd), nil
+	}
+}
+
+// Label returns a NodeRule that parses the rule pr. When pr fails without advancing, the labels that it expected are
+// replaced by l. Such as "expression" instead of "number, identifier or '('".
+func Label[Tt any, Tn any](pr NodeRule[Tt, Tn], l string) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		m := r.Mark()
+		er, isRecorder := r.(expectationRecorder)
+
+		var (
+			fp int
+			fl []string
+		)
+
+		if isRecorder {
+			fp, fl = er.expected()
+			fl = append([]string(nil), fl...)
+		}
+
+		nd, err := pr(r, f)
+
+		if err == nil || !isSyntaxFailure(err) {
+			return nd, err
+		}
+
+		r.Reset(m)
+
+		if isRecorder {
+			if p, _ := er.expected(); p != m {
+				return nd, err
+			}
+
+			if fp == m {
+				er.restore(fp, fl)
+			} else {
+				er.restore(m, nil)
+			}
+
+			er.expect(m, l)
+			return nd, expectedError(r, l)
+		}
+
+		var se *parser.SyntaxError
+
+		if errors.As(err, &se) && se.Position == m {
+			return nd, expectedError(r, l)
+		}
+
+		return nd, err
+	}
+}
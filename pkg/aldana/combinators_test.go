@@ -0,0 +1,109 @@
+package aldana
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func leafNode(t string) *node {
+	return &node{Type: "leaf", Value: t}
+}
+
+func listNode(nds []*node) *node {
+	return &node{Type: "list", Children: nds}
+}
+
+func isToken(v string) TokenPredicate[string] {
+	return func(t string) bool {
+		return t == v
+	}
+}
+
+func setUpCombinatorParser() parser.Parser[string, *node] {
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+	num := Token(isDigit, "digit", leafNode)
+
+	return NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program": Many1(Rule[string, *node]("statement"), listNode),
+			"statement": Alt(
+				Seq(func(nds []*node) *node {
+					return &node{Type: "call", Value: nds[0].Value, Children: nds[1].Children}
+				}, Token(isIdentifier, "identifier", leafNode), Between(sym("("), SepBy(Rule[string, *node]("value"), sym(","), listNode), sym(")")), sym(";")),
+				Seq(func(nds []*node) *node {
+					return &node{Type: "assign", Value: nds[0].Value, Children: []*node{nds[2]}}
+				}, Token(isIdentifier, "identifier", leafNode), sym("="), Rule[string, *node]("value"), sym(";")),
+			),
+			"value": Label(Alt(num, Token(isIdentifier, "identifier", leafNode), Seq(func(nds []*node) *node {
+				if nds[0] != nil {
+					return &node{Type: "neg", Children: nds[1:]}
+				}
+				return nds[1]
+			}, Optional(sym("-")), sym("("), Rule[string, *node]("value"), sym(")"))), "value"),
+		},
+		Root: "program",
+	})
+}
+
+func isIdentifier(t string) bool {
+	return len(t) == 1 && t[0] >= 'a' && t[0] <= 'z'
+}
+
+/*
+Given: a parser made by combinators.
+When: parses acceptable tokens.
+Then: returns the node and no error, backtracking between the alternatives.
+*/
+func TestCombinators_with_acceptable_tokens(t *testing.T) {
+	// arrange
+	prs := setUpCombinatorParser()
+
+	// act
+	nd, err := prs.Parse(NewReader([]string{"f", "(", "1", ",", "x", ")", ";", "a", "=", "-", "(", "2", ")", ";", "g", "(", ")", ";"}))
+
+	// assert
+	assert.NoError(t, err)
+	var ty []string
+	for _, c := range nd.Children {
+		ty = append(ty, c.Type+":"+c.Value)
+	}
+	assert.Equal(t, []string{"call:f", "assign:a", "call:g"}, ty)
+	assert.Len(t, nd.Children[0].Children, 2)
+	assert.Equal(t, "neg", nd.Children[1].Children[0].Type)
+}
+
+/*
+Given: a parser made by combinators.
+When: parses non acceptable tokens.
+Then: returns a parser.SyntaxError at the furthest position with the merged or labeled expectations.
+*/
+func TestCombinators_with_non_acceptable_tokens(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		expect string
+	}{
+		"merged labels":  {[]string{"f", "1"}, "expected '(' or '=' but found 1"},
+		"labeled rule":   {[]string{"a", "=", ";"}, "expected value but found ;"},
+		"separated list": {[]string{"f", "(", "1", ",", ")", ";"}, "expected value but found )"},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// arrange
+			prs := setUpCombinatorParser()
+
+			// act
+			_, err := prs.Parse(NewReader(c.tokens))
+
+			// assert
+			var sErr *parser.SyntaxError
+			assert.ErrorAs(t, err, &sErr)
+			assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+			assert.Equal(t, c.expect, sErr.Error())
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package aldana
+
+import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+
+// Context is the user state of a single parse: a stack of values of C, where the top is the current one.
+type Context[C any] struct {
+	stack []C
+}
+
+// Value returns the current value.
+func (c *Context[C]) Value() C {
+	return c.stack[len(c.stack)-1]
+}
+
+// Set replaces the current value by v.
+func (c *Context[C]) Set(v C) {
+	c.stack[len(c.stack)-1] = v
+}
+
+// Push makes v the current value, until it is popped.
+func (c *Context[C]) Push(v C) {
+	c.stack = append(c.stack, v)
+}
+
+// Pop removes the current value and returns it. The value given to the parse is never removed.
+func (c *Context[C]) Pop() C {
+	v := c.Value()
+
+	if len(c.stack) > 1 {
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+
+	return v
+}
+
+// Depth returns the number of pushed values.
+func (c *Context[C]) Depth() int {
+	return len(c.stack) - 1
+}
+
+// ContextNodeRule is a NodeRule that receives the Context of the parse.
+type ContextNodeRule[Tt any, Tn any, C any] func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn], c *Context[C]) (Tn, error)
+
+// Scoped returns a ContextNodeRule that parses the rule pr with the value made by v from the current one, which is popped
+// after pr even when it fails.
+//
+// # Example
+//
+//	"loop": Scoped(func(l LoopState) LoopState {
+//		l.Inside = true
+//		return l
+//	}, parseLoop)
+func Scoped[Tt any, Tn any, C any](v func(c C) C, pr ContextNodeRule[Tt, Tn, C]) ContextNodeRule[Tt, Tn, C] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn], c *Context[C]) (Tn, error) {
+		c.Push(v(c.Value()))
+		defer c.Pop()
+
+		return pr(r, f, c)
+	}
+}
+
+// ContextParser is a parser.Parser whose rules receive a Context, which starts with a value given per parse.
+type ContextParser[Tt any, Tn any, C any] interface {
+	parser.Parser[Tt, Tn]
+	// ParseContext parses the tokens of the reader r, with c as the value of the Context.
+	ParseContext(r parser.Reader[Tt], c C) (Tn, error)
+}
+
+// contextParser implements ContextParser by the default parser, whose bound rules receive the Context of the parse.
+type contextParser[Tt any, Tn any, C any] struct {
+	prs *defaultParser[Tt, Tn]
+}
+
+// bind returns the options with the rules bound to the Context c.
+func bind[Tt any, Tn any, C any](ops *ParserOptions[Tt, Tn], rules map[string]ContextNodeRule[Tt, Tn, C], c *Context[C]) *ParserOptions[Tt, Tn] {
+	o := *ops
+	o.ParseRules = make(map[string]NodeRule[Tt, Tn], len(ops.ParseRules)+len(rules))
+
+	for n, r := range ops.ParseRules {
+		o.ParseRules[n] = r
+	}
+
+	for n, r := range rules {
+		r := r
+		o.ParseRules[n] = func(rd parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+			return r(rd, f, c)
+		}
+	}
+
+	return &o
+}
+
+func (p *contextParser[Tt, Tn, C]) ParseContext(r parser.Reader[Tt], c C) (Tn, error) {
+	return p.prs.parseRoot(r, &Context[C]{stack: []C{c}})
+}
+
+// Parse parses the tokens of the reader r with the zero value of C as the value of the Context, which is nil when C is a
+// pointer.
+func (p *contextParser[Tt, Tn, C]) Parse(r parser.Reader[Tt]) (Tn, error) {
+	return p.ParseContext(r, *new(C))
+}
+
+// NewContextParser returns a ContextParser with the rules of the options ops and the context rules, which take the place of
+// the rules with the same name.
+//
+// # About the implementation
+//   - Every parse has its own Context, so the parser can be used concurrently.
+//   - The rules are bound once, and every parse gives its Context to them. So a parse only allocates its Context.
+//   - Parse gives the zero value of C to the Context, which is nil when C is a pointer. Use ParseContext for give a value.
+//   - The changes to the values are not undone when the rules backtrack, only the Scoped values are popped.
+//   - The options are validated once, with a Context of the zero value, as NewParser does.
+//
+// # Example
+//
+//	prs := NewContextParser(&ParserOptions[*Token, *Node]{Root: "program"}, map[string]ContextNodeRule[*Token, *Node, *Symbols]{
+//		"program": parseProgram,
+//		"declaration": func(r parser.Reader[*Token], f ParseRuleFinder[*Token, *Node], c *Context[*Symbols]) (*Node, error) {
+//			nd, err := parseDeclaration(r, f)
+//			if err == nil {
+//				c.Value().Declare(nd.Name)
+//			}
+//			return nd, err
+//		},
+//	})
+//
+//	nd, err := prs.ParseContext(NewReader(tks), NewSymbols())
+func NewContextParser[Tt any, Tn any, C any](ops *ParserOptions[Tt, Tn], rules map[string]ContextNodeRule[Tt, Tn, C]) ContextParser[Tt, Tn, C] {
+	prs := newParser(ops)
+	prs.invalid = bind(ops, rules, &Context[C]{stack: []C{*new(C)}}).check()
+	prs.bound = make(map[string]boundRule[Tt, Tn], len(rules))
+
+	for n, r := range rules {
+		r := r
+		prs.bound[n] = func(rd parser.Reader[Tt], f ParseRuleFinder[Tt, Tn], v any) (Tn, error) {
+			return r(rd, f, v.(*Context[C]))
+		}
+	}
+
+	return &contextParser[Tt, Tn, C]{prs: prs}
+}
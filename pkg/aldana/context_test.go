@@ -0,0 +1,171 @@
+package aldana
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+var errBreakOutsideLoop = errors.New("break outside a loop")
+
+// loopState is the context of the loops: whether the statements are inside a loop, and the count of breaks.
+type loopState struct {
+	inside bool
+	breaks *int
+}
+
+func setUpContextParser() ContextParser[string, *node, loopState] {
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+
+	return NewContextParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program":   Many1(Rule[string, *node]("statement"), listNode),
+			"statement": Alt(Rule[string, *node]("break"), Rule[string, *node]("loop")),
+		},
+		Root:   "program",
+		Strict: true,
+	}, map[string]ContextNodeRule[string, *node, loopState]{
+		"break": func(r parser.Reader[string], f ParseRuleFinder[string, *node], c *Context[loopState]) (*node, error) {
+			nd, err := sym("break")(r, f)
+			if err != nil {
+				return nil, err
+			}
+			if !c.Value().inside {
+				return nil, errBreakOutsideLoop
+			}
+			if c.Value().breaks != nil {
+				*c.Value().breaks++
+			}
+			return nd, nil
+		},
+		"loop": Scoped(func(s loopState) loopState {
+			s.inside = true
+			return s
+		}, func(r parser.Reader[string], f ParseRuleFinder[string, *node], c *Context[loopState]) (*node, error) {
+			return Seq(listNode, sym("loop"), Between(sym("{"), Many(Rule[string, *node]("statement"), listNode), sym("}")))(r, f)
+		}),
+	})
+}
+
+/*
+Given: a context with pushed values.
+When: pops the values.
+Then: returns them from the last to the first, keeping the initial one.
+*/
+func TestContext(t *testing.T) {
+	// arrange
+	c := &Context[int]{stack: []int{1}}
+
+	// act
+	c.Push(2)
+	c.Set(3)
+	c.Push(4)
+	d := c.Depth()
+	v := []int{c.Pop(), c.Pop(), c.Pop()}
+
+	// assert
+	assert.Equal(t, 2, d)
+	assert.Equal(t, []int{4, 3, 1}, v)
+	assert.Equal(t, 0, c.Depth())
+	assert.Equal(t, 1, c.Value())
+}
+
+/*
+Given: a context parser whose rules depend on the scoped context.
+When: parses tokens with different initial contexts.
+Then: the rules see the scoped values, which are popped after their rule.
+*/
+func TestContextParser_ParseContext(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		inside bool
+		err    error
+	}{
+		"break inside a loop":    {tokens: []string{"loop", "{", "loop", "{", "break", "}", "break", "}"}},
+		"break after a loop":     {tokens: []string{"loop", "{", "}", "break"}, err: errBreakOutsideLoop},
+		"break inside a context": {tokens: []string{"break", "break"}, inside: true},
+	}
+
+	prs := setUpContextParser()
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			_, err := prs.ParseContext(NewReader(c.tokens), loopState{inside: c.inside})
+
+			// assert
+			if c.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+}
+
+/*
+Given: a context parser.
+When: parses tokens concurrently, with a context per parse.
+Then: every parse changes its own context only.
+*/
+func TestContextParser_ParseContext_concurrently(t *testing.T) {
+	// arrange
+	prs := setUpContextParser()
+	counts := make([]int, 8)
+
+	// act
+	var wg sync.WaitGroup
+
+	for i := range counts {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			tks := []string{"loop", "{"}
+			for j := 0; j < i; j++ {
+				tks = append(tks, "break")
+			}
+
+			_, _ = prs.ParseContext(NewReader(append(tks, "}")), loopState{breaks: &counts[i]})
+		}(i)
+	}
+
+	wg.Wait()
+
+	// assert
+	for i, n := range counts {
+		assert.Equal(t, i, n)
+	}
+}
+
+/*
+Given: a context parser whose context is a pointer.
+When: parses tokens without and with a value of the context.
+Then: the rules receive nil as the value of Parse, and the given value of ParseContext.
+*/
+func TestContextParser_Parse_with_zero_value(t *testing.T) {
+	// arrange
+	var values []*int
+	prs := NewContextParser(&ParserOptions[string, *node]{Root: "item"}, map[string]ContextNodeRule[string, *node, *int]{
+		"item": func(r parser.Reader[string], f ParseRuleFinder[string, *node], c *Context[*int]) (*node, error) {
+			values = append(values, c.Value())
+			return Many1(Token(isDigit, "digit", leafNode), listNode)(r, f)
+		},
+	})
+	v := 7
+
+	// act
+	_, err := prs.Parse(NewReader([]string{"1", "2"}))
+	_, cErr := prs.ParseContext(NewReader([]string{"1", "2"}), &v)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, cErr)
+	assert.Equal(t, []*int{nil, &v}, values)
+}
@@ -0,0 +1,168 @@
+package cst
+
+import (
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+)
+
+// Options are the options to build the tree of the tokens of a source.
+type Options[Tt any] struct {
+	// Kind returns the kind of a token.
+	Kind func(t Tt) string
+	// Offsets returns the offsets of the source where a token starts, and just after where it ends.
+	Offsets func(t Tt) (int, int)
+}
+
+// SpanOffsets returns the offsets of a token by its lexer.Span, as read by the default cursor, where the column of a
+// position is the offset of its byte plus one.
+func SpanOffsets[Tt lexer.Spanned](t Tt) (int, int) {
+	s := t.GetSpan()
+	return s.Start.Column - 1, s.End.Column - 1
+}
+
+// callKey identifies the calls of a rule that read the same tokens.
+type callKey struct {
+	rule       string
+	start, end int
+}
+
+// treeBuilder builds the tree of the calls to the rules of a parse.
+type treeBuilder[Tt any] struct {
+	*Builder
+	tks []Tt
+	ops *Options[Tt]
+	// calls are the calls with children by their tokens, so the memoized calls are built as the first one.
+	calls map[callKey]*aldana.RuleCall
+	// built are the nodes by their tokens, which are shared.
+	built map[callKey]*Green
+}
+
+// index records the calls with children of the calls cs and their descendants.
+func (b *treeBuilder[Tt]) index(cs []*aldana.RuleCall) {
+	for _, c := range cs {
+		k := callKey{rule: c.Rule, start: c.Start, end: c.End}
+
+		if _, ok := b.calls[k]; !ok && c.Err == nil && len(c.Calls) != 0 {
+			b.calls[k] = c
+		}
+
+		b.index(c.Calls)
+	}
+}
+
+// tokens adds the tokens from the index i to j.
+func (b *treeBuilder[Tt]) tokens(i int, j int) {
+	for ; i < j && i < len(b.tks); i++ {
+		s, e := b.ops.Offsets(b.tks[i])
+		b.Token(b.ops.Kind(b.tks[i]), s, e)
+	}
+}
+
+// children adds the kept calls of cs, and the tokens between them, from the index s to e.
+func (b *treeBuilder[Tt]) children(cs []*aldana.RuleCall, s int, e int) {
+	i := s
+
+	for _, c := range kept(cs, s, e) {
+		b.tokens(i, c.Start)
+		b.call(c)
+		i = c.End
+	}
+
+	b.tokens(i, e)
+}
+
+// call adds the node of the call c.
+func (b *treeBuilder[Tt]) call(c *aldana.RuleCall) {
+	k := callKey{rule: c.Rule, start: c.Start, end: c.End}
+
+	if c.Start < c.End {
+		s, _ := b.ops.Offsets(b.tks[c.Start])
+		b.Trivia(s)
+	}
+
+	if g, ok := b.built[k]; ok {
+		b.Add(g)
+		return
+	}
+
+	if f, ok := b.calls[k]; ok {
+		c = f
+	}
+
+	b.Start(c.Rule)
+	b.children(c.Calls, c.Start, c.End)
+	b.built[k] = b.Finish()
+}
+
+// kept returns the calls of cs that succeeded inside the tokens from the index s to e. Where a call that reads the tokens of
+// a previous one takes its place, because the previous one was backtracked.
+func kept(cs []*aldana.RuleCall, s int, e int) []*aldana.RuleCall {
+	var k []*aldana.RuleCall
+
+	for _, c := range cs {
+		if c.Err != nil || c.Start < s || c.End > e {
+			continue
+		}
+
+		for len(k) != 0 && k[len(k)-1].End > c.Start {
+			k = k[:len(k)-1]
+		}
+
+		k = append(k, c)
+	}
+
+	return k
+}
+
+// Build returns the tree of the source src, with a node per successful call to the rules of the trace calls, and a token
+// per token of tks.
+//
+// # About the implementation
+//   - The children of a node are the calls of its rule that were not backtracked, and the tokens that the rule read itself.
+//   - The bytes of the source between the tokens, such as spaces and comments, are added as Trivia tokens before the
+//     outermost node that starts at the next token. So the Text of a node is its tokens, and the Text of the root is the
+//     source byte for byte.
+//   - The calls that read the same tokens share the same Green node, so the memoized calls are built as the first one.
+func Build[Tt any](src []byte, tks []Tt, calls []*aldana.RuleCall, ops *Options[Tt]) *Node {
+	b := &treeBuilder[Tt]{
+		Builder: NewBuilder(src),
+		tks:     tks,
+		ops:     ops,
+		calls:   map[callKey]*aldana.RuleCall{},
+		built:   map[callKey]*Green{},
+	}
+
+	b.index(calls)
+	b.children(calls, 0, len(tks))
+
+	return b.Tree()
+}
+
+// Parse parses the tokens tks of the source src with the default parser of the options ops, and returns the node of the
+// rules, the tree of the source and the error of the parse. The tree is returned even when the parse fails.
+//
+// # About the implementation
+//   - The calls to the rules are traced by a new ParseTracer, which takes the place of the Tracer of the options. So the tree
+//     is built by the calls of its own parse, even when the options are shared by concurrent parses.
+//   - The tree is built by Build, so the rules are the nodes of the tree without changing them.
+//
+// # Example
+//
+//	nd, tree, err := cst.Parse(prsOps, src, tks, &cst.Options[*token.Token[Kind]]{
+//		Kind:    func(t *token.Token[Kind]) string { return t.Kind.String() },
+//		Offsets: cst.SpanOffsets[*token.Token[Kind]],
+//	})
+//
+//	fmt.Print(tree.Text() == string(src)) // true
+func Parse[Tt any, Tn any](ops *aldana.ParserOptions[Tt, Tn], src []byte, tks []Tt, o *Options[Tt]) (Tn, *Node, error) {
+	var calls []*aldana.RuleCall
+
+	po := *ops
+	po.Tracer = aldana.NewParseTracerFunc(func(cs []*aldana.RuleCall) {
+		calls = cs
+	})
+
+	nd, err := aldana.NewParser(&po).Parse(aldana.NewReader(tks))
+
+	return nd, Build(src, tks, calls, o), err
+}
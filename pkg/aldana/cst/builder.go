@@ -0,0 +1,137 @@
+package cst
+
+// frame is a node that is being built.
+type frame struct {
+	kind     string
+	children []*Green
+}
+
+// tokenKey identifies the shared tokens of a Builder.
+type tokenKey struct {
+	kind string
+	text string
+}
+
+// Builder builds a tree of a source, where the tokens are added in the order of the source, and the bytes between them are
+// added as Trivia tokens.
+type Builder struct {
+	src []byte
+	// pos is the offset just after the last added token.
+	pos    int
+	stack  []frame
+	tokens map[tokenKey]*Green
+}
+
+// Start starts a node of kind k, which contains the next tokens and nodes until its Finish.
+func (b *Builder) Start(k string) {
+	b.stack = append(b.stack, frame{kind: k})
+}
+
+// Finish finishes the last started node, and returns it.
+func (b *Builder) Finish() *Green {
+	f := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+
+	g := NewNode(f.kind, f.children...)
+	b.add(g)
+
+	return g
+}
+
+// Trivia adds the bytes of the source from the last token to the offset s as a Trivia token. So they are added before the
+// node that is started after it, instead of inside it.
+func (b *Builder) Trivia(s int) {
+	if s > len(b.src) {
+		s = len(b.src)
+	}
+
+	if s > b.pos {
+		b.add(b.token(Trivia, string(b.src[b.pos:s])))
+		b.pos = s
+	}
+}
+
+// Token adds a token of kind k, with the text of the source from the offset s to e. Where the bytes of the source from the
+// last token to s are added before it as a Trivia token.
+func (b *Builder) Token(k string, s int, e int) {
+	b.Trivia(s)
+	b.add(b.token(k, string(b.src[s:e])))
+	b.pos = e
+}
+
+// Add adds the Green node g, which was built from the same source at the offset just after the last token.
+func (b *Builder) Add(g *Green) {
+	b.add(g)
+	b.pos += g.width
+}
+
+// Tree finishes the started nodes, adds the bytes after the last token as a Trivia token, and returns the root.
+// Where the top level is the root when it has a single node, otherwise it is wrapped in a node of kind Source.
+func (b *Builder) Tree() *Node {
+	for len(b.stack) > 1 {
+		b.Finish()
+	}
+
+	b.Trivia(len(b.src))
+
+	top := b.stack[0].children
+	b.stack[0].children = nil
+
+	root := -1
+	for i, g := range top {
+		if g.IsTrivia() {
+			continue
+		}
+		if root != -1 || g.token {
+			return NewRoot(NewNode(Source, top...))
+		}
+		root = i
+	}
+
+	if root == -1 {
+		return NewRoot(NewNode(Source, top...))
+	}
+
+	cs := append(append(top[:root:root], top[root].children...), top[root+1:]...)
+
+	return NewRoot(NewNode(top[root].kind, cs...))
+}
+
+func (b *Builder) add(g *Green) {
+	f := &b.stack[len(b.stack)-1]
+	f.children = append(f.children, g)
+}
+
+// token returns the token of kind k with the text s, which is shared by the tokens of the same kind and text.
+func (b *Builder) token(k string, s string) *Green {
+	key := tokenKey{kind: k, text: s}
+
+	if g, ok := b.tokens[key]; ok {
+		return g
+	}
+
+	g := NewToken(k, s)
+	b.tokens[key] = g
+
+	return g
+}
+
+// NewBuilder returns a Builder of the tree of the source src.
+//
+// # Example
+//
+//	b := NewBuilder([]byte("x = 1"))
+//	b.Start("assignment")
+//	b.Token("identifier", 0, 1)
+//	b.Token("=", 2, 3)
+//	b.Token("number", 4, 5)
+//	b.Finish()
+//
+//	root := b.Tree()
+func NewBuilder(src []byte) *Builder {
+	return &Builder{
+		src:    src,
+		stack:  []frame{{}},
+		tokens: map[tokenKey]*Green{},
+	}
+}
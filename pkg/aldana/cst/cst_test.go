@@ -0,0 +1,209 @@
+package cst
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/token"
+	"github.com/stretchr/testify/assert"
+)
+
+type tk = *token.Token[string]
+
+const src = "# totals\na = 1;  # one\nb=22 ;\nc;\n"
+
+// omit is a token rule that reads the chars until the end of the line, and omits them.
+func omit(c lexer.Cursor, r ranges.ByteRange) (tk, bool, error) {
+	for c.HasChar() && c.GetChar() != '\n' {
+		c.Next()
+	}
+	c.Next()
+	c.AddLine(1)
+	return nil, false, nil
+}
+
+func setUpTokens(t *testing.T) []tk {
+	lex := aldana.NewLexer(&aldana.LexerOptions[tk]{
+		Ignore: aldana.IgnoreWhiteSpaces(),
+		LexRules: []aldana.LexicalRule[tk]{
+			token.NewLexicalRule("identifier", ranges.ByteBounded('a', 'z')),
+			token.NewLexicalRule("number", ranges.ByteBounded('0', '9')),
+			token.NewLexicalRule("=", ranges.ByteSingle('=')),
+			token.NewLexicalRule(";", ranges.ByteSingle(';')),
+			aldana.NewFallibleLexicalRule(ranges.ByteSet('#', '\n'), omit),
+		},
+	})
+
+	tks, err := lex.Tokenize(aldana.NewCursor([]byte(src)))
+	assert.NoError(t, err)
+
+	return tks
+}
+
+func setUpParserOptions() *aldana.ParserOptions[tk, string] {
+	join := func(nds []string) string {
+		return strings.Join(nds, " ")
+	}
+	sym := func(k string) aldana.NodeRule[tk, string] {
+		return aldana.Token(token.IsKind(k), k, func(t tk) string {
+			return t.Text()
+		})
+	}
+
+	return &aldana.ParserOptions[tk, string]{
+		ParseRules: map[string]aldana.NodeRule[tk, string]{
+			"program":    aldana.Many1(aldana.Rule[tk, string]("statement"), join),
+			"statement":  aldana.Alt(aldana.Rule[tk, string]("assignment"), aldana.Rule[tk, string]("expression")),
+			"assignment": aldana.Seq(join, aldana.Rule[tk, string]("target"), sym("="), sym("number"), sym(";")),
+			"expression": aldana.Seq(join, aldana.Rule[tk, string]("target"), sym(";")),
+			"target":     aldana.Rule[tk, string]("name"),
+			"name":       sym("identifier"),
+		},
+		Root:    "program",
+		Memoize: true,
+	}
+}
+
+var ops = &Options[tk]{
+	Kind: func(t tk) string {
+		return t.Kind
+	},
+	Offsets: SpanOffsets[tk],
+}
+
+func kinds(ns []*Node) []string {
+	ks := make([]string, len(ns))
+	for i, n := range ns {
+		ks[i] = n.Kind()
+	}
+	return ks
+}
+
+/*
+Given: the tokens of a source with spaces and comments.
+When: parses them building the tree.
+Then: returns the tree of the rules with the trivia, whose text is the source.
+*/
+func TestParse(t *testing.T) {
+	// arrange
+	tks := setUpTokens(t)
+
+	// act
+	nd, tree, err := Parse(setUpParserOptions(), []byte(src), tks, ops)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "a = 1 ; b = 22 ; c ;", nd)
+	assert.Equal(t, src, tree.Text())
+	assert.Equal(t, "program", tree.Kind())
+	assert.Equal(t, []string{Trivia, "statement", Trivia, "statement", Trivia, "statement", Trivia}, kinds(tree.Children()))
+
+	second := tree.Children()[3]
+	assert.Equal(t, "statement@23..29", second.String())
+	assert.Equal(t, "b=22 ;", second.Text())
+	assert.Same(t, tree.Green(), second.Parent().Green())
+
+	assignment := second.Child("assignment")
+	assert.Equal(t, []string{"target", "=", "number", Trivia, ";"}, kinds(assignment.Children()))
+	assert.Equal(t, []string{"b", "=", "22", ";"}, texts(assignment.Tokens()))
+
+	expression := tree.Children()[5].Child("expression")
+	assert.Equal(t, []string{"target", ";"}, kinds(expression.Children()))
+	assert.Equal(t, "name", expression.Child("target").Child("name").Kind())
+}
+
+func texts(ns []*Node) []string {
+	ts := make([]string, len(ns))
+	for i, n := range ns {
+		ts[i] = n.Text()
+	}
+	return ts
+}
+
+/*
+Given: a parsed tree.
+When: replaces a token of the tree.
+Then: returns a new tree with the token, which shares the other nodes, and the old tree is not changed.
+*/
+func TestNode_Replace(t *testing.T) {
+	// arrange
+	_, tree, _ := Parse(setUpParserOptions(), []byte(src), setUpTokens(t), ops)
+	name := tree.Children()[3].Child("assignment").Child("target").Child("name").Children()[0]
+
+	// act
+	renamed := name.Replace(NewToken("identifier", "total"))
+
+	// assert
+	assert.Equal(t, "total", renamed.Text())
+	assert.Equal(t, 23, renamed.Offset())
+	assert.Equal(t, strings.Replace(src, "b=", "total=", 1), renamed.Root().Text())
+	assert.Equal(t, src, tree.Text())
+	assert.Same(t, tree.Children()[1].Green(), renamed.Root().Children()[1].Green())
+}
+
+type assignment struct {
+	*Node
+}
+
+func (a assignment) Name() string {
+	return a.Child("target").Text()
+}
+
+/*
+Given: a parsed tree and a typed view of the assignments.
+When: casts and finds the nodes of the view.
+Then: returns the views of the nodes of its kind only.
+*/
+func TestView(t *testing.T) {
+	// arrange
+	_, tree, _ := Parse(setUpParserOptions(), []byte(src), setUpTokens(t), ops)
+	assignments := NewView("assignment", func(n *Node) assignment {
+		return assignment{n}
+	})
+
+	// act
+	all := assignments.Descendants(tree)
+	_, rootOk := assignments.Cast(tree)
+	first, firstOk := assignments.Child(tree.Children()[1])
+	_, lastOk := assignments.Child(tree.Children()[5])
+
+	// assert
+	assert.Len(t, all, 2)
+	assert.Equal(t, "a", all[0].Name())
+	assert.Equal(t, "b", all[1].Name())
+	assert.False(t, rootOk)
+	assert.True(t, firstOk)
+	assert.Equal(t, "a = 1;", first.Text())
+	assert.False(t, lastOk)
+}
+
+/*
+Given: a builder of a source.
+When: builds nodes and tokens at the top level.
+Then: returns a root of kind Source with the trivia, and shares the equal tokens.
+*/
+func TestBuilder(t *testing.T) {
+	// arrange
+	b := NewBuilder([]byte(" x = x "))
+
+	// act
+	b.Trivia(1)
+	b.Start("name")
+	b.Token("identifier", 1, 2)
+	b.Finish()
+	b.Token("=", 3, 4)
+	b.Trivia(5)
+	b.Start("name")
+	b.Token("identifier", 5, 6)
+	root := b.Tree()
+
+	// assert
+	assert.Equal(t, " x = x ", root.Text())
+	assert.Equal(t, Source, root.Kind())
+	assert.Equal(t, []string{Trivia, "name", Trivia, "=", Trivia, "name", Trivia}, kinds(root.Children()))
+	assert.Same(t, root.Children()[1].Green().Children()[0], root.Children()[5].Green().Children()[0])
+	assert.Equal(t, 7, root.Green().Width())
+}
@@ -0,0 +1,86 @@
+// Package cst contains a lossless concrete syntax tree, which reproduces its source byte for byte, including the trivia.
+package cst
+
+import "strings"
+
+const (
+	// Trivia is the kind of the tokens made by the bytes of the source between the tokens, such as spaces and comments.
+	Trivia = "#trivia"
+	// Source is the kind of the node that wraps the top level of a tree, when it is not a single node.
+	Source = "#source"
+)
+
+// Green is an immutable node of the tree, without its position nor its parent, so the same node can be shared by many
+// trees. Where a token has the text of the source, and a node has the children.
+type Green struct {
+	kind     string
+	text     string
+	children []*Green
+	width    int
+	token    bool
+}
+
+// Kind returns the kind of the node: the name of the rule, or the kind of the token.
+func (g *Green) Kind() string {
+	return g.kind
+}
+
+// IsToken returns a boolean that indicates whether the node is a token.
+func (g *Green) IsToken() bool {
+	return g.token
+}
+
+// IsTrivia returns a boolean that indicates whether the node is a token of Trivia.
+func (g *Green) IsTrivia() bool {
+	return g.token && g.kind == Trivia
+}
+
+// Width returns the number of bytes of the text of the node.
+func (g *Green) Width() int {
+	return g.width
+}
+
+// Children returns a copy of the children of the node. Nil for the tokens.
+func (g *Green) Children() []*Green {
+	return append([]*Green(nil), g.children...)
+}
+
+// Text returns the text of the node: the text of the token, or the text of all the children.
+func (g *Green) Text() string {
+	if g.token {
+		return g.text
+	}
+
+	var b strings.Builder
+	b.Grow(g.width)
+	g.write(&b)
+
+	return b.String()
+}
+
+func (g *Green) write(b *strings.Builder) {
+	if g.token {
+		b.WriteString(g.text)
+		return
+	}
+
+	for _, c := range g.children {
+		c.write(b)
+	}
+}
+
+// NewToken returns a Green token of kind k with the text s.
+func NewToken(k string, s string) *Green {
+	return &Green{kind: k, text: s, width: len(s), token: true}
+}
+
+// NewNode returns a Green node of kind k with the children cs.
+func NewNode(k string, cs ...*Green) *Green {
+	g := &Green{kind: k, children: append([]*Green(nil), cs...)}
+
+	for _, c := range cs {
+		g.width += c.width
+	}
+
+	return g
+}
@@ -0,0 +1,140 @@
+package cst
+
+import "fmt"
+
+// Node is a node of the tree at its position: a Green node with its parent and its offset in the source.
+// The nodes are made on demand, so two Node of the same Green at the same offset are equal, but not the same.
+type Node struct {
+	green  *Green
+	parent *Node
+	// index is the index of the node in the children of its parent.
+	index  int
+	offset int
+}
+
+// Green returns the Green node.
+func (n *Node) Green() *Green {
+	return n.green
+}
+
+// Kind returns the kind of the node.
+func (n *Node) Kind() string {
+	return n.green.kind
+}
+
+// IsToken returns a boolean that indicates whether the node is a token.
+func (n *Node) IsToken() bool {
+	return n.green.IsToken()
+}
+
+// IsTrivia returns a boolean that indicates whether the node is a token of Trivia.
+func (n *Node) IsTrivia() bool {
+	return n.green.IsTrivia()
+}
+
+// Parent returns the parent of the node. Nil for the root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Root returns the root of the tree of the node.
+func (n *Node) Root() *Node {
+	for n.parent != nil {
+		n = n.parent
+	}
+
+	return n
+}
+
+// Offset returns the offset of the first byte of the node in the source.
+func (n *Node) Offset() int {
+	return n.offset
+}
+
+// End returns the offset just after the last byte of the node in the source.
+func (n *Node) End() int {
+	return n.offset + n.green.width
+}
+
+// Text returns the text of the node, which is the source from the Offset to the End.
+func (n *Node) Text() string {
+	return n.green.Text()
+}
+
+// String returns the kind and the offsets of the node as "kind@offset..end".
+func (n *Node) String() string {
+	return fmt.Sprintf("%s@%d..%d", n.green.kind, n.offset, n.End())
+}
+
+// Children returns the children of the node, including the tokens and the trivia.
+func (n *Node) Children() []*Node {
+	cs := make([]*Node, len(n.green.children))
+	o := n.offset
+
+	for i, g := range n.green.children {
+		cs[i] = &Node{green: g, parent: n, index: i, offset: o}
+		o += g.width
+	}
+
+	return cs
+}
+
+// Child returns the first child of kind k. Nil when there is none.
+func (n *Node) Child(k string) *Node {
+	for _, c := range n.Children() {
+		if c.green.kind == k {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Walk calls f with the node and its descendants in the order of the source. Where f returns whether to walk the children
+// of the node.
+func (n *Node) Walk(f func(n *Node) bool) {
+	if !f(n) {
+		return
+	}
+
+	for _, c := range n.Children() {
+		c.Walk(f)
+	}
+}
+
+// Tokens returns the tokens of the node and its descendants, without the trivia.
+func (n *Node) Tokens() []*Node {
+	var ts []*Node
+
+	n.Walk(func(c *Node) bool {
+		if c.IsToken() && !c.IsTrivia() {
+			ts = append(ts, c)
+		}
+		return !c.IsToken()
+	})
+
+	return ts
+}
+
+// Replace returns the node of g in a new tree, which is the tree of n with g in its place. The other nodes are shared.
+//
+// # Example
+//
+//	renamed := name.Replace(cst.NewToken("identifier", "total")).Root()
+func (n *Node) Replace(g *Green) *Node {
+	if n.parent == nil {
+		return &Node{green: g}
+	}
+
+	cs := n.parent.green.Children()
+	cs[n.index] = g
+
+	p := n.parent.Replace(NewNode(n.parent.green.kind, cs...))
+
+	return &Node{green: g, parent: p, index: n.index, offset: n.offset}
+}
+
+// NewRoot returns the Node of the root g.
+func NewRoot(g *Green) *Node {
+	return &Node{green: g}
+}
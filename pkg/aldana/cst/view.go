@@ -0,0 +1,71 @@
+package cst
+
+// View is a typed view of the nodes of a kind, such as the nodes of an AST layered on the tree.
+type View[V any] struct {
+	// Kind is the kind of the viewed nodes.
+	Kind string
+	// New returns the view of a node of the Kind.
+	New func(n *Node) V
+}
+
+// Cast returns the view of the node n, and a boolean that indicates whether it is of the Kind.
+func (v View[V]) Cast(n *Node) (V, bool) {
+	if n == nil || n.Kind() != v.Kind {
+		return *new(V), false
+	}
+
+	return v.New(n), true
+}
+
+// Child returns the view of the first child of the node n of the Kind, and a boolean that indicates whether it exists.
+func (v View[V]) Child(n *Node) (V, bool) {
+	return v.Cast(n.Child(v.Kind))
+}
+
+// Children returns the views of the children of the node n of the Kind.
+func (v View[V]) Children(n *Node) []V {
+	var vs []V
+
+	for _, c := range n.Children() {
+		if w, ok := v.Cast(c); ok {
+			vs = append(vs, w)
+		}
+	}
+
+	return vs
+}
+
+// Descendants returns the views of the descendants of the node n of the Kind, in the order of the source.
+func (v View[V]) Descendants(n *Node) []V {
+	var vs []V
+
+	for _, c := range n.Children() {
+		c.Walk(func(d *Node) bool {
+			if w, ok := v.Cast(d); ok {
+				vs = append(vs, w)
+			}
+			return true
+		})
+	}
+
+	return vs
+}
+
+// NewView returns a View of the nodes of kind k, made by f.
+//
+// # Example
+//
+//	type Assignment struct{ *cst.Node }
+//
+//	func (a Assignment) Name() string {
+//		return a.Child("identifier").Text()
+//	}
+//
+//	var Assignments = cst.NewView("assignment", func(n *cst.Node) Assignment { return Assignment{n} })
+//
+//	for _, a := range Assignments.Descendants(tree) {
+//		fmt.Println(a.Name())
+//	}
+func NewView[V any](k string, f func(n *Node) V) View[V] {
+	return View[V]{Kind: k, New: f}
+}
@@ -35,6 +35,16 @@ func (c *defaultCursor) Next() {
 	}
 }
 
+func (c *defaultCursor) Peek(n int) (byte, bool) {
+	i := c.column - 1 + n
+
+	if !c.hasChar || n < 0 || i >= c.length {
+		return 0, false
+	}
+
+	return c.content[i], true
+}
+
 func (c *defaultCursor) AddLine(l int) {
 	c.line += l
 }
@@ -43,6 +53,7 @@ func (c *defaultCursor) AddLine(l int) {
 //
 // # About this implementation
 //   - the cursor can read when the column value is less than the length of the reading bytes.
+//   - the cursor implements lexer.Peeker, so the lookahead rules can peek the chars.
 //
 // # Example
 //
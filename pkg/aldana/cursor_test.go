@@ -183,6 +183,56 @@ func TestCursor_HasToken_with_reaching(t *testing.T) {
 	})
 }
 
+/*
+Given: a n-len byte array.
+When: peeks the chars after the current one.
+Then: returns the chars without advancing the cursor, or false when they do not exist.
+*/
+func TestCursor_Peek(t *testing.T) {
+	t.Run("current and next", func(t *testing.T) {
+		// arrange
+		cur := setUpCursor([]byte("123"))
+
+		// act
+		cur.Next()
+		c0, ok0 := cur.(lexer.Peeker).Peek(0)
+		c2, ok2 := cur.(lexer.Peeker).Peek(2)
+
+		// assert
+		assert.True(t, ok0)
+		assert.Equal(t, byte('1'), c0)
+		assert.True(t, ok2)
+		assert.Equal(t, byte('3'), c2)
+		assert.Equal(t, byte('1'), cur.GetChar())
+	})
+
+	t.Run("over-reaching", func(t *testing.T) {
+		// arrange
+		cur := setUpCursor([]byte("123"))
+
+		// act
+		cur.Next()
+		cur.Next()
+		_, ok := cur.(lexer.Peeker).Peek(2)
+
+		// assert
+		assert.False(t, ok)
+	})
+
+	t.Run("no chars", func(t *testing.T) {
+		// arrange
+		cur := setUpCursor([]byte("1"))
+
+		// act
+		cur.Next()
+		cur.Next()
+		_, ok := cur.(lexer.Peeker).Peek(0)
+
+		// assert
+		assert.False(t, ok)
+	})
+}
+
 func setUpCursor(c []byte) lexer.Cursor {
 	return NewCursor(c)
 }
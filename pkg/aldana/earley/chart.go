@@ -0,0 +1,167 @@
+package earley
+
+import (
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/bnf"
+)
+
+// item is a production with a dot before the symbol dot, started at the token origin.
+type item struct {
+	prod   int
+	dot    int
+	origin int
+}
+
+// set is the set of the items before a token.
+type set struct {
+	items []item
+	index map[item]bool
+	// waits are the items whose dot is before a non-terminal, indexed by the non-terminal.
+	waits map[int][]item
+	// done are the origins of the completed non-terminals, indexed by the non-terminal.
+	done map[int]map[int]bool
+	// matches are the terminals already tried against the token of the set.
+	matches map[int]bool
+}
+
+func newSet() *set {
+	return &set{index: map[item]bool{}, waits: map[int][]item{}, done: map[int]map[int]bool{}, matches: map[int]bool{}}
+}
+
+// chart is the Earley recognizer of the tokens.
+type chart[Tt any] struct {
+	b      *bnf.BNF
+	preds  []aldana.TokenPredicate[Tt]
+	tokens []Tt
+	sets   []*set
+}
+
+// match returns a boolean that indicates whether the token k is the terminal x.
+func (c *chart[Tt]) match(x int, k int) bool {
+	s := c.sets[k]
+
+	m, ok := s.matches[x]
+	if !ok {
+		m = x != bnf.End && c.preds[x](c.tokens[k])
+		s.matches[x] = m
+	}
+
+	return m
+}
+
+func (c *chart[Tt]) add(k int, it item) {
+	s := c.sets[k]
+
+	if s.index[it] {
+		return
+	}
+
+	s.index[it] = true
+	s.items = append(s.items, it)
+
+	p := c.b.Prods[it.prod]
+
+	if it.dot < len(p.RHS) {
+		if x := p.RHS[it.dot]; !c.b.Syms[x].Terminal {
+			s.waits[x] = append(s.waits[x], it)
+		}
+		return
+	}
+
+	if s.done[p.LHS] == nil {
+		s.done[p.LHS] = map[int]bool{}
+	}
+
+	s.done[p.LHS][it.origin] = true
+}
+
+// recognize fills the sets of the tokens, and returns the index of the last set that has items.
+// The tokens are accepted when the last set is the one after all of them and it has the completed start.
+//
+// The nullable non-terminals are skipped as soon as they are predicted, as Aycock and Horspool do, so the items that
+// wait them after their completion are advanced too.
+func (c *chart[Tt]) recognize() int {
+	n := len(c.tokens)
+
+	c.sets = make([]*set, n+1)
+	for i := range c.sets {
+		c.sets[i] = newSet()
+	}
+
+	c.add(0, item{prod: bnf.Accept})
+
+	for k := 0; k <= n; k++ {
+		s := c.sets[k]
+
+		if len(s.items) == 0 {
+			return k - 1
+		}
+
+		for i := 0; i < len(s.items); i++ {
+			it := s.items[i]
+			p := c.b.Prods[it.prod]
+
+			if it.dot == len(p.RHS) {
+				for _, w := range c.sets[it.origin].waits[p.LHS] {
+					c.add(k, item{prod: w.prod, dot: w.dot + 1, origin: w.origin})
+				}
+				continue
+			}
+
+			x := p.RHS[it.dot]
+
+			if c.b.Syms[x].Terminal {
+				if k < n && c.match(x, k) {
+					c.add(k+1, item{prod: it.prod, dot: it.dot + 1, origin: it.origin})
+				}
+				continue
+			}
+
+			for _, q := range c.b.Syms[x].Prods {
+				c.add(k, item{prod: q, origin: k})
+			}
+
+			if c.b.Nullable[x] {
+				c.add(k, item{prod: it.prod, dot: it.dot + 1, origin: it.origin})
+			}
+		}
+	}
+
+	return n
+}
+
+// accepted returns a boolean that indicates whether the start is completed at the set k.
+func (c *chart[Tt]) accepted(k int) bool {
+	return c.sets[k].index[item{prod: bnf.Accept, dot: 1}]
+}
+
+// expected returns the labels of the terminals expected at the set k, where the literals are before the kinds.
+func (c *chart[Tt]) expected(k int) []string {
+	var x []int
+	seen := map[int]bool{}
+
+	if c.accepted(k) {
+		x, seen[bnf.End] = append(x, bnf.End), true
+	}
+
+	for _, it := range c.sets[k].items {
+		p := c.b.Prods[it.prod]
+
+		if it.dot == len(p.RHS) {
+			continue
+		}
+
+		if t := p.RHS[it.dot]; c.b.Syms[t].Terminal && !seen[t] {
+			x, seen[t] = append(x, t), true
+		}
+	}
+
+	c.b.SortTerminals(x)
+
+	l := make([]string, len(x))
+	for i, t := range x {
+		l[i] = c.b.Syms[t].Label()
+	}
+
+	return l
+}
@@ -0,0 +1,223 @@
+package earley
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/grammartest"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const exprGrammar = `expr = expr "+" expr | expr "*" expr | expr "==" expr | "-" expr | NUMBER ;`
+
+var (
+	sum    = Production{Rule: "expr", Alt: 0}
+	mul    = Production{Rule: "expr", Alt: 1}
+	eq     = Production{Rule: "expr", Alt: 2}
+	negate = Production{Rule: "expr", Alt: 3}
+)
+
+func setUpParser(t *testing.T, src string, fs ...Filter) Parser[string, *grammar.Tree[string]] {
+	g, err := grammar.Parse(src)
+	assert.NoError(t, err)
+	return NewParser(g, grammartest.Options(), fs...)
+}
+
+func setUpExprParser(t *testing.T) Parser[string, *grammar.Tree[string]] {
+	return setUpParser(t, exprGrammar,
+		Priority([]Production{negate}, []Production{mul}, []Production{sum}, []Production{eq}),
+		Assoc(aldana.LeftAssociative, sum),
+		Assoc(aldana.LeftAssociative, mul),
+		Assoc(aldana.NonAssociative, eq),
+	)
+}
+
+/*
+Given: an ambiguous grammar without filters.
+When: parses the forest of ambiguous tokens.
+Then: returns the forest with all the trees, sharing the equal nodes.
+*/
+func TestParser_ParseForest(t *testing.T) {
+	// arrange
+	prs := setUpParser(t, exprGrammar)
+
+	// act
+	f1, err1 := prs.ParseForest(aldana.NewReader([]string{"1", "+", "2", "+", "3"}))
+	f2, err2 := prs.ParseForest(aldana.NewReader([]string{"1", "+", "2", "*", "3", "+", "4"}))
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, "{(expr (expr 1) + (expr (expr 2) + (expr 3))) | (expr (expr (expr 1) + (expr 2)) + (expr 3))}", f1.String())
+	assert.Equal(t, 2, f1.Trees())
+	assert.Equal(t, 5, f2.Trees())
+	assert.Equal(t, 0, f2.Start)
+	assert.Equal(t, 7, f2.End)
+	assert.Same(t, f1.Alts[0].Children[0], f1.Alts[1].Children[0].Alts[0].Children[0])
+}
+
+/*
+Given: an ambiguous grammar without filters.
+When: parses ambiguous tokens.
+Then: returns an ErrAmbiguous.
+*/
+func TestParser_Parse_ambiguous(t *testing.T) {
+	// arrange
+	prs := setUpParser(t, exprGrammar)
+
+	// act
+	_, err := prs.Parse(aldana.NewReader([]string{"1", "+", "2", "+", "3"}))
+
+	// assert
+	assert.ErrorIs(t, err, ErrAmbiguous)
+	assert.EqualError(t, err, "the tokens are ambiguous: expr from 0 to 5 has the derivations [expr/0 expr/0]")
+}
+
+/*
+Given: an ambiguous grammar with priority and associativity filters.
+When: parses ambiguous tokens.
+Then: returns the single tree allowed by the filters.
+*/
+func TestParser_Parse_with_filters(t *testing.T) {
+	tests := map[string]struct {
+		tks  []string
+		tree string
+	}{
+		"left associative": {
+			tks:  []string{"1", "+", "2", "+", "3"},
+			tree: "(expr (expr (expr 1) + (expr 2)) + (expr 3))",
+		},
+		"priority": {
+			tks:  []string{"1", "+", "2", "*", "3", "+", "4"},
+			tree: "(expr (expr (expr 1) + (expr (expr 2) * (expr 3))) + (expr 4))",
+		},
+		"prefix priority": {
+			tks:  []string{"-", "1", "*", "2", "==", "3"},
+			tree: "(expr (expr (expr - (expr 1)) * (expr 2)) == (expr 3))",
+		},
+		"unambiguous": {
+			tks:  []string{"-", "-", "1"},
+			tree: "(expr - (expr - (expr 1)))",
+		},
+	}
+
+	prs := setUpExprParser(t)
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			// act
+			tr, err := prs.Parse(aldana.NewReader(tt.tks))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.tree, tr.String())
+		})
+	}
+}
+
+/*
+Given: a non associative production.
+When: parses tokens where the production is next to itself.
+Then: returns an ErrRejected.
+*/
+func TestParser_Parse_rejected(t *testing.T) {
+	// arrange
+	prs := setUpExprParser(t)
+
+	// act
+	_, err := prs.Parse(aldana.NewReader([]string{"1", "==", "2", "==", "3"}))
+
+	// assert
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+/*
+Given: a grammar with EBNF expressions and keywords that are kinds too.
+When: parses acceptable tokens.
+Then: returns the tree where the nodes of the EBNF expressions belong to their production.
+*/
+func TestParser_Parse_with_ebnf(t *testing.T) {
+	// arrange
+	prs := setUpParser(t, `
+		program = decl+ ;
+		decl    = "let" IDENT [ "=" value ] ";" | IDENT "=" value ";" ;
+		value   = NUMBER | IDENT | "[" [ value { "," value } ] "]" ;
+	`)
+
+	// act
+	tr, err := prs.Parse(aldana.NewReader([]string{"let", "let", ";", "b", "=", "[", "1", ",", "let", "]", ";", "c", "=", "[", "]", ";"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "(program (decl let let ;) (decl b = (value [ (value 1) , (value let) ]) ;) (decl c = (value [ ]) ;))", tr.String())
+}
+
+/*
+Given: a grammar.
+When: parses unacceptable tokens.
+Then: returns a parser.SyntaxError with the terminals expected at the first unexpected token.
+*/
+func TestParser_Parse_syntax_error(t *testing.T) {
+	tests := map[string]struct {
+		tks []string
+		err string
+	}{
+		"unexpected token": {
+			tks: []string{"1", "+", "*"},
+			err: "expected '-' or NUMBER but found *",
+		},
+		"unexpected end": {
+			tks: []string{"1", "*"},
+			err: "expected '-' or NUMBER but found the end of the tokens",
+		},
+		"expected end": {
+			tks: []string{"1", "2"},
+			err: "expected '+', '*', '==' or end of the tokens but found 2",
+		},
+	}
+
+	prs := setUpExprParser(t)
+
+	for n, tt := range tests {
+		t.Run(n, func(t *testing.T) {
+			// act
+			_, err := prs.Parse(aldana.NewReader(tt.tks))
+
+			// assert
+			assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+/*
+Given: grammars whose EBNF repetitions derive the empty tokens in a cycle.
+When: parses a single token.
+Then: returns the tree without the cyclic derivations, instead of recursing without bound.
+*/
+func TestParser_Parse_with_nullable_cycles(t *testing.T) {
+	cases := map[string]struct {
+		grammar string
+		expect  string
+	}{
+		"repeated optional production": {grammar: `a = "x" b* ; b = "y"? ;`, expect: "(a x)"},
+		"repeated repetition":          {grammar: `a = { "x" }* ;`, expect: "(a x)"},
+		"repeated optional":            {grammar: `a = ("x"?)* ;`, expect: "(a x)"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs := setUpParser(t, c.grammar)
+
+			// act
+			tr, err := prs.Parse(aldana.NewReader([]string{"x"}))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, tr.String())
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package earley
+
+import "errors"
+
+var (
+	ErrAmbiguous = errors.New("the tokens are ambiguous")
+	ErrRejected  = errors.New("every derivation of the tokens was discarded by the filters")
+)
@@ -0,0 +1,185 @@
+package earley
+
+import (
+	"fmt"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+)
+
+// Filter is a function that returns a boolean that indicates whether a node derived by the production child can be the
+// leftmost child (left is true) or the rightmost child of a node derived by the production parent.
+// The filters only discard the derivations of the ambiguous operators, such as expr = expr "+" expr, where an operand
+// can be derived by another operator.
+type Filter func(parent Production, child Production, left bool) bool
+
+// Priority returns a Filter where the productions of a level cannot be the leftmost or rightmost child of the productions
+// of the previous levels. Where the first level binds the tightest.
+//
+// # Example
+//
+//	// 1 + 2 * 3 is 1 + (2 * 3)
+//	Priority(
+//		[]Production{{Rule: "expr", Alt: 1}}, // expr "*" expr
+//		[]Production{{Rule: "expr", Alt: 0}}, // expr "+" expr
+//	)
+func Priority(levels ...[]Production) Filter {
+	lv := map[Production]int{}
+
+	for i, ps := range levels {
+		for _, p := range ps {
+			lv[p] = i
+		}
+	}
+
+	return func(parent Production, child Production, _ bool) bool {
+		pl, ok := lv[parent]
+		if !ok {
+			return true
+		}
+
+		cl, ok := lv[child]
+
+		return !ok || cl <= pl
+	}
+}
+
+// Assoc returns a Filter where the productions ps are grouped by the associativity a among them.
+//   - aldana.LeftAssociative: they cannot be the rightmost child of each other, 1 - 2 - 3 is (1 - 2) - 3.
+//   - aldana.RightAssociative: they cannot be the leftmost child of each other, 1 ^ 2 ^ 3 is 1 ^ (2 ^ 3).
+//   - aldana.NonAssociative: they cannot be a child of each other, 1 == 2 == 3 is rejected.
+func Assoc(a aldana.Associativity, ps ...Production) Filter {
+	g := map[Production]bool{}
+
+	for _, p := range ps {
+		g[p] = true
+	}
+
+	return func(parent Production, child Production, left bool) bool {
+		if !g[parent] || !g[child] {
+			return true
+		}
+
+		switch a {
+		case aldana.LeftAssociative:
+			return left
+		case aldana.RightAssociative:
+			return !left
+		default:
+			return false
+		}
+	}
+}
+
+// filterer applies the filters to a forest.
+type filterer[Tt any] struct {
+	fs    []Filter
+	nodes map[*Node[Tt]]*Node[Tt]
+}
+
+// allowed returns a boolean that indicates whether the alternative child is allowed at the position i of the n children
+// of the alternative parent.
+func (f *filterer[Tt]) allowed(parent Production, child Production, i int, n int) bool {
+	for _, flt := range f.fs {
+		if i == 0 && !flt(parent, child, true) {
+			return false
+		}
+		if i == n-1 && !flt(parent, child, false) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// restrict returns the node c without the alternatives that are not allowed at the position i of the n children of the
+// alternative parent, or nil when none is allowed.
+func (f *filterer[Tt]) restrict(parent Production, c *Node[Tt], i int, n int) *Node[Tt] {
+	var alts []*Alternative[Tt]
+
+	for _, a := range c.Alts {
+		if f.allowed(parent, a.Production, i, n) {
+			alts = append(alts, a)
+		}
+	}
+
+	switch len(alts) {
+	case 0:
+		return nil
+	case len(c.Alts):
+		return c
+	}
+
+	r := *c
+	r.Alts = alts
+
+	return &r
+}
+
+// node returns the node n without the alternatives discarded by the filters, or nil when all of them are discarded.
+// The nodes are restricted by their parents, so a shared node is copied when its parents allow different alternatives.
+func (f *filterer[Tt]) node(n *Node[Tt]) *Node[Tt] {
+	if n.IsLeaf() {
+		return n
+	}
+
+	if r, ok := f.nodes[n]; ok {
+		return r
+	}
+
+	r := &Node[Tt]{Rule: n.Rule, Start: n.Start, End: n.End}
+
+alts:
+	for _, a := range n.Alts {
+		chs := make([]*Node[Tt], len(a.Children))
+
+		for i, c := range a.Children {
+			if c.IsLeaf() {
+				chs[i] = c
+				continue
+			}
+
+			if c = f.node(c); c != nil && (i == 0 || i == len(chs)-1) {
+				c = f.restrict(a.Production, c, i, len(chs))
+			}
+
+			if c == nil {
+				continue alts
+			}
+
+			chs[i] = c
+		}
+
+		r.Alts = append(r.Alts, &Alternative[Tt]{Production: a.Production, Children: chs})
+	}
+
+	if len(r.Alts) == 0 {
+		r = nil
+	}
+
+	f.nodes[n] = r
+
+	return r
+}
+
+// ambiguity returns an ErrAmbiguous of the first ambiguous node of the forest n, or nil when it is a tree.
+func ambiguity[Tt any](n *Node[Tt]) error {
+	if n.IsLeaf() {
+		return nil
+	}
+
+	if n.IsAmbiguous() {
+		ps := make([]Production, len(n.Alts))
+		for i, a := range n.Alts {
+			ps[i] = a.Production
+		}
+		return fmt.Errorf("%w: %s from %d to %d has the derivations %v", ErrAmbiguous, n.Rule, n.Start, n.End, ps)
+	}
+
+	for _, c := range n.Alts[0].Children {
+		if err := ambiguity(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package earley
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Production identifies an alternative of a production of the grammar: by its name, and by the index of the alternative
+// when its expression is a choice, otherwise 0.
+type Production struct {
+	Rule string
+	Alt  int
+}
+
+func (p Production) String() string {
+	return fmt.Sprintf("%s/%d", p.Rule, p.Alt)
+}
+
+// Alternative is a derivation of a Node by a production.
+type Alternative[Tt any] struct {
+	Production
+	// Children are the nodes of the matched terminals and non-terminals, where the ones of the EBNF expressions belong
+	// to the production.
+	Children []*Node[Tt]
+}
+
+// Node is a node of a parse forest. Where the leaves are the matched tokens, and the branches are the productions that
+// derive a range of the tokens by one or more alternatives.
+// The nodes of equal production and range are shared by all their parents.
+type Node[Tt any] struct {
+	// Rule is the name of the production. Empty for the leaves.
+	Rule string
+	// Token is the matched token of the leaves.
+	Token Tt
+	// Start and End are the positions of the first token and the one after the last token.
+	Start int
+	End   int
+	// Alts are the derivations of the branches. More than one when the tokens are ambiguous.
+	Alts []*Alternative[Tt]
+}
+
+// IsLeaf returns a boolean that indicates whether the node is a matched token.
+func (n *Node[Tt]) IsLeaf() bool {
+	return n.Rule == ""
+}
+
+// IsAmbiguous returns a boolean that indicates whether the node has more than one derivation.
+func (n *Node[Tt]) IsAmbiguous() bool {
+	return len(n.Alts) > 1
+}
+
+// Trees returns the number of trees of the forest.
+func (n *Node[Tt]) Trees() int {
+	return n.trees(map[*Node[Tt]]int{})
+}
+
+func (n *Node[Tt]) trees(memo map[*Node[Tt]]int) int {
+	if n.IsLeaf() {
+		return 1
+	}
+
+	if c, ok := memo[n]; ok {
+		return c
+	}
+
+	c := 0
+
+	for _, a := range n.Alts {
+		ac := 1
+		for _, ch := range a.Children {
+			ac *= ch.trees(memo)
+		}
+		c += ac
+	}
+
+	memo[n] = c
+
+	return c
+}
+
+// String returns the forest as a s-expression: (rule child...), where the leaves are their tokens and the ambiguous nodes
+// are their alternatives between braces: {(rule child...) | (rule child...)}.
+func (n *Node[Tt]) String() string {
+	var b strings.Builder
+	n.write(&b)
+	return b.String()
+}
+
+func (n *Node[Tt]) write(b *strings.Builder) {
+	if n.IsLeaf() {
+		fmt.Fprintf(b, "%v", n.Token)
+		return
+	}
+
+	if n.IsAmbiguous() {
+		b.WriteString("{")
+	}
+
+	for i, a := range n.Alts {
+		if i != 0 {
+			b.WriteString(" | ")
+		}
+
+		b.WriteString("(")
+		b.WriteString(n.Rule)
+
+		for _, c := range a.Children {
+			b.WriteString(" ")
+			c.write(b)
+		}
+
+		b.WriteString(")")
+	}
+
+	if n.IsAmbiguous() {
+		b.WriteString("}")
+	}
+}
+
+// span is a range of the tokens derived by a symbol.
+type span struct {
+	sym   int
+	start int
+	end   int
+}
+
+// step is a prefix of a production: the symbols before the dot that derive a range of the tokens.
+type step struct {
+	prod  int
+	dot   int
+	start int
+	end   int
+}
+
+// forest builds the Nodes of a recognized chart.
+type forest[Tt any] struct {
+	c *chart[Tt]
+	// base is the position of the first token.
+	base   int
+	leaves map[int]*Node[Tt]
+	nodes  map[span]*Node[Tt]
+	// helpers are the children of the helpers by span.
+	helpers map[span][][]*Node[Tt]
+	// building are the nodes and the helpers whose derivations are being built, so a derivation that needs them is cyclic.
+	building map[span]bool
+	steps    map[step][][]*Node[Tt]
+}
+
+func newForest[Tt any](c *chart[Tt], base int) *forest[Tt] {
+	return &forest[Tt]{
+		c:        c,
+		base:     base,
+		leaves:   map[int]*Node[Tt]{},
+		nodes:    map[span]*Node[Tt]{},
+		helpers:  map[span][][]*Node[Tt]{},
+		building: map[span]bool{},
+		steps:    map[step][][]*Node[Tt]{},
+	}
+}
+
+func (f *forest[Tt]) leaf(k int) *Node[Tt] {
+	l, ok := f.leaves[k]
+
+	if !ok {
+		l = &Node[Tt]{Token: f.c.tokens[k], Start: f.base + k, End: f.base + k + 1}
+		f.leaves[k] = l
+	}
+
+	return l
+}
+
+// node returns the node of the non-terminal x that derives the tokens from i to j, or nil when it does not derive them.
+// The cyclic derivations, such as a = a, are discarded.
+func (f *forest[Tt]) node(x int, i int, j int) *Node[Tt] {
+	k := span{sym: x, start: i, end: j}
+
+	if n, ok := f.nodes[k]; ok || f.building[k] {
+		return n
+	}
+
+	f.building[k] = true
+	defer delete(f.building, k)
+
+	s := f.c.b.Syms[x]
+	n := &Node[Tt]{Rule: s.Name, Start: f.base + i, End: f.base + j}
+
+	for _, q := range s.Prods {
+		d := len(f.c.b.Prods[q].RHS)
+
+		if !f.c.sets[j].index[item{prod: q, dot: d, origin: i}] {
+			continue
+		}
+
+		for _, ch := range f.derive(q, d, i, j) {
+			n.Alts = append(n.Alts, &Alternative[Tt]{
+				Production: Production{Rule: s.Name, Alt: f.c.b.Prods[q].Alt},
+				Children:   ch,
+			})
+		}
+	}
+
+	if len(n.Alts) == 0 {
+		n = nil
+	}
+
+	f.nodes[k] = n
+
+	return n
+}
+
+// children returns the children of the non-terminal x that derives the tokens from i to j, by each derivation.
+// The non-terminals have their node as single child, and the helpers have the children of their productions. As for the
+// nodes, the cyclic derivations of the helpers, such as the repetitions of the empty tokens, are discarded.
+func (f *forest[Tt]) children(x int, i int, j int) [][]*Node[Tt] {
+	s := f.c.b.Syms[x]
+
+	if !s.Helper {
+		if n := f.node(x, i, j); n != nil {
+			return [][]*Node[Tt]{{n}}
+		}
+		return nil
+	}
+
+	k := span{sym: x, start: i, end: j}
+
+	if chs, ok := f.helpers[k]; ok || f.building[k] {
+		return chs
+	}
+
+	f.building[k] = true
+	defer delete(f.building, k)
+
+	var chs [][]*Node[Tt]
+
+	for _, q := range s.Prods {
+		d := len(f.c.b.Prods[q].RHS)
+
+		if f.c.sets[j].index[item{prod: q, dot: d, origin: i}] {
+			chs = append(chs, f.derive(q, d, i, j)...)
+		}
+	}
+
+	f.helpers[k] = chs
+
+	return chs
+}
+
+// derive returns the children of the symbols before the dot d of the production p that derive the tokens from i to j, by
+// each derivation.
+func (f *forest[Tt]) derive(p int, d int, i int, j int) [][]*Node[Tt] {
+	if d == 0 {
+		if i == j {
+			return [][]*Node[Tt]{{}}
+		}
+		return nil
+	}
+
+	k := step{prod: p, dot: d, start: i, end: j}
+
+	if chs, ok := f.steps[k]; ok {
+		return chs
+	}
+
+	var chs [][]*Node[Tt]
+
+	prev := item{prod: p, dot: d - 1, origin: i}
+	x := f.c.b.Prods[p].RHS[d-1]
+
+	join := func(m int, last [][]*Node[Tt]) {
+		if len(last) == 0 {
+			return
+		}
+
+		for _, pre := range f.derive(p, d-1, i, m) {
+			for _, l := range last {
+				ch := make([]*Node[Tt], 0, len(pre)+len(l))
+				chs = append(chs, append(append(ch, pre...), l...))
+			}
+		}
+	}
+
+	if f.c.b.Syms[x].Terminal {
+		if m := j - 1; m >= i && f.c.sets[m].index[prev] && f.c.match(x, m) {
+			join(m, [][]*Node[Tt]{{f.leaf(m)}})
+		}
+	} else {
+		for m := i; m <= j; m++ {
+			if f.c.sets[m].index[prev] && f.c.sets[j].done[x][m] {
+				join(m, f.children(x, m, j))
+			}
+		}
+	}
+
+	f.steps[k] = chs
+
+	return chs
+}
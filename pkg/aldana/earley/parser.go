@@ -0,0 +1,144 @@
+// Package earley contains a parser for any context-free grammar, including the ambiguous ones, by the Earley algorithm.
+package earley
+
+import (
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/bnf"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// Parser is a parser.Parser that can return all the trees of the tokens as a forest.
+type Parser[Tt any, Tn any] interface {
+	parser.Parser[Tt, Tn]
+	// ParseForest returns the forest of the tokens after the filters, which can have ambiguous nodes.
+	ParseForest(r parser.Reader[Tt]) (*Node[Tt], error)
+}
+
+// earleyParser implements Parser by an Earley chart.
+type earleyParser[Tt any, Tn any] struct {
+	b *bnf.BNF
+	// start is the symbol of the start production.
+	start int
+	// preds are the predicates of the terminals, indexed by symbol.
+	preds []aldana.TokenPredicate[Tt]
+	fs    []Filter
+	ops   *grammar.ParserOptions[Tt, Tn]
+}
+
+func (p *earleyParser[Tt, Tn]) ParseForest(r parser.Reader[Tt]) (*Node[Tt], error) {
+	r.Next()
+
+	base := r.Position()
+	c := &chart[Tt]{b: p.b, preds: p.preds}
+
+	for t, ok := r.Peek(0); ok; t, ok = r.Peek(0) {
+		c.tokens = append(c.tokens, t)
+		r.Next()
+	}
+
+	k := c.recognize()
+
+	if k < len(c.tokens) {
+		return nil, parser.NewSyntaxError(c.tokens[k], base+k, c.expected(k)...)
+	}
+
+	if !c.accepted(k) {
+		return nil, parser.NewSyntaxError(nil, base+k, c.expected(k)...)
+	}
+
+	n := newForest(c, base).node(p.start, 0, k)
+
+	if n == nil {
+		return nil, ErrRejected
+	}
+
+	f := &filterer[Tt]{fs: p.fs, nodes: map[*Node[Tt]]*Node[Tt]{}}
+
+	if n = f.node(n); n == nil {
+		return nil, ErrRejected
+	}
+
+	return n, nil
+}
+
+// build returns the node of the tree n, made by the leaves and actions of the options.
+func (p *earleyParser[Tt, Tn]) build(n *Node[Tt]) Tn {
+	if n.IsLeaf() {
+		return p.ops.Leaf(n.Token)
+	}
+
+	chs := n.Alts[0].Children
+	nds := make([]Tn, len(chs))
+
+	for i, c := range chs {
+		nds[i] = p.build(c)
+	}
+
+	return p.ops.Action(n.Rule)(nds)
+}
+
+func (p *earleyParser[Tt, Tn]) Parse(r parser.Reader[Tt]) (Tn, error) {
+	n, err := p.ParseForest(r)
+
+	if err != nil {
+		return *new(Tn), err
+	}
+
+	if err := ambiguity(n); err != nil {
+		return *new(Tn), err
+	}
+
+	return p.build(n), nil
+}
+
+// NewParser returns a Parser for the grammar g, which matches the tokens and builds the nodes by the options ops.
+// The filters fs discard the derivations of the ambiguous tokens, so Parse returns the single remaining tree.
+// The Rules of the options are not used.
+//
+// # About the implementation
+//   - The EBNF expressions are replaced by helper non-terminals, whose nodes belong to their parent.
+//   - Every terminal that matches a token is tried, so keywords can be kinds too, such as "let" and IDENT.
+//   - The derivations of equal production and range are shared in the forest, so it is not exponential in the tokens.
+//   - The cyclic derivations, such as a = a, are discarded.
+//   - When the tokens are not accepted, a parser.SyntaxError is returned at the first token that no item expects.
+//   - When a node remains with more than one derivation after the filters, Parse returns an ErrAmbiguous with its range.
+//
+// # Example
+//
+//	g, _ := grammar.Parse(`expr = expr "+" expr | expr "*" expr | NUMBER ;`)
+//
+//	prs := NewParser(g, ops,
+//		Priority([]Production{{Rule: "expr", Alt: 1}}, []Production{{Rule: "expr", Alt: 0}}),
+//		Assoc(aldana.LeftAssociative, Production{Rule: "expr", Alt: 0}),
+//		Assoc(aldana.LeftAssociative, Production{Rule: "expr", Alt: 1}),
+//	)
+func NewParser[Tt any, Tn any](g *grammar.Grammar, ops *grammar.ParserOptions[Tt, Tn], fs ...Filter) Parser[Tt, Tn] {
+	s := ops.Start
+
+	if s == "" {
+		s = g.Start()
+	}
+
+	b := bnf.New(g, s)
+
+	p := &earleyParser[Tt, Tn]{
+		b:     b,
+		start: b.Prods[bnf.Accept].RHS[0],
+		preds: make([]aldana.TokenPredicate[Tt], len(b.Syms)),
+		fs:    fs,
+		ops:   ops,
+	}
+
+	for i, sym := range b.Syms {
+		switch {
+		case i == bnf.End || !sym.Terminal:
+		case sym.Literal:
+			p.preds[i] = ops.Literal(sym.Value)
+		default:
+			p.preds[i] = ops.Kind(sym.Value)
+		}
+	}
+
+	return p
+}
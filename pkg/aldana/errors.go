@@ -3,8 +3,10 @@ package aldana
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
 )
 
 var (
@@ -12,9 +14,81 @@ var (
 	ErrNotFoundRootParserRule = errors.New("the root parser rule was not found")
 	ErrNotFundParserRule      = errors.New("the parser rule was not found")
 	ErrEmptyBytes             = errors.New("the no bytes resulted after the transpilation")
+	ErrLeftRecursion          = fmt.Errorf("%w: the rule is left recursive", parser.ErrInvalidSyntax)
+	ErrDuplicatedParserRule   = errors.New("the parser rule is already declared")
+	ErrUnusedParserRule       = errors.New("the parser rule is never referred")
+	ErrUnreachableParserRule  = errors.New("the parser rule is not reachable from the root")
+	ErrPanickedParserRule     = errors.New("the parser rule panicked without tokens")
 )
 
 func GetLexerError(err error, c lexer.Cursor) error {
 	ln, cl := c.GetPosition()
 	return fmt.Errorf("%s %s at: line %d column %d", err, string(c.GetChar()), ln, cl)
 }
+
+// GetTokenError returns the error with the token and, when it implements lexer.Spanned, its position.
+func GetTokenError(err error, t any) error {
+	if s, ok := t.(lexer.Spanned); ok {
+		p := s.GetSpan().Start
+		return fmt.Errorf("%w %v at: line %d column %d", err, t, p.Line, p.Column)
+	}
+	return fmt.Errorf("%w %v", err, t)
+}
+
+// ParseError is the error of a NodeRule, with the stack of the rules that were being parsed.
+// Use errors.As for get it, and errors.Is for check its cause.
+type ParseError struct {
+	// Stack are the names of the rules, from the root to the one that failed.
+	Stack []string
+	// Token is the token where the rule failed. Nil at the end of the tokens.
+	Token any
+	// Position is the index of the Token.
+	Position int
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+
+	if len(e.Stack) != 0 {
+		b.WriteString(strings.Join(e.Stack, " > "))
+		b.WriteString(": ")
+	}
+
+	b.WriteString(e.Err.Error())
+
+	var sErr *parser.SyntaxError
+
+	if s, ok := e.Token.(lexer.Spanned); ok && !errors.As(e.Err, &sErr) {
+		fmt.Fprintf(&b, " at %s", s.GetSpan().Start)
+	}
+
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns the err as a ParseError, positioned at the current token of the reader.
+// When err already is a ParseError, it is returned as is.
+func newParseError[T any](err error, s []string, r parser.Reader[T]) error {
+	var pErr *ParseError
+
+	if errors.As(err, &pErr) {
+		return err
+	}
+
+	pErr = &ParseError{
+		Stack:    append([]string(nil), s...),
+		Position: r.Position(),
+		Err:      err,
+	}
+
+	if t, ok := r.Peek(0); ok {
+		pErr.Token = t
+	}
+
+	return pErr
+}
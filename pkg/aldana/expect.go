@@ -0,0 +1,110 @@
+package aldana
+
+import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+
+// TokenPredicate is a function that indicates whether a token satisfies a condition.
+type TokenPredicate[T any] func(t T) bool
+
+// expectationRecorder is implemented by the readers that record the labels expected at the furthest failure.
+type expectationRecorder interface {
+	// expect records that the label l was expected at the position p.
+	expect(p int, l string)
+	// expected returns the furthest position with failures and the labels expected there.
+	expected() (int, []string)
+	// restore sets the furthest position p and its labels l, as returned by expected.
+	restore(p int, l []string)
+}
+
+// expectations implements expectationRecorder. Use it as embedded struct.
+type expectations struct {
+	furthest int
+	labels   []string
+}
+
+func (e *expectations) expect(p int, l string) {
+	if e.labels == nil || p > e.furthest {
+		e.furthest, e.labels = p, []string{l}
+		return
+	}
+
+	if p < e.furthest {
+		return
+	}
+
+	for _, v := range e.labels {
+		if v == l {
+			return
+		}
+	}
+
+	e.labels = append(e.labels, l)
+}
+
+func (e *expectations) expected() (int, []string) {
+	return e.furthest, e.labels
+}
+
+func (e *expectations) restore(p int, l []string) {
+	e.furthest, e.labels = p, l
+}
+
+// Accept returns the current token and advances the reader when the token satisfies p.
+// The returned boolean indicates whether the token was accepted. Otherwise, the label l is recorded as expected.
+//
+// # Example
+//
+//	if _, ok := Accept(r, IsComma, "','"); ok {
+//		nd.Children = append(nd.Children, parseArg(r))
+//	}
+func Accept[T any](r parser.Reader[T], p TokenPredicate[T], l string) (T, bool) {
+	if t, ok := r.Peek(0); ok && p(t) {
+		r.Next()
+		return t, true
+	}
+
+	if er, ok := r.(expectationRecorder); ok {
+		er.expect(r.Position(), l)
+	}
+
+	return *new(T), false
+}
+
+// Expect returns the current token and advances the reader when the token satisfies p.
+// Otherwise, returns a parser.SyntaxError with all the labels expected at the furthest position that was reached.
+//
+// # Example
+//
+//	func parseVar(r parser.Reader[*Token], f ParseRuleFinder[*Token, *Node]) (*Node, error) {
+//		if _, err := Expect(r, IsKeyword("let"), "'let'"); err != nil {
+//			return nil, err
+//		}
+//		id, err := Expect(r, IsIdentifier, "identifier")
+//		if err != nil {
+//			return nil, err
+//		}
+//		...
+//	}
+func Expect[T any](r parser.Reader[T], p TokenPredicate[T], l string) (T, error) {
+	if t, ok := Accept(r, p, l); ok {
+		return t, nil
+	}
+
+	return *new(T), expectedError(r, l)
+}
+
+// expectedError returns a parser.SyntaxError with the labels expected at the furthest position of r, or with l when r
+// does not record them.
+func expectedError[T any](r parser.Reader[T], l string) error {
+	ps, ls := r.Position(), []string{l}
+
+	if er, ok := r.(expectationRecorder); ok {
+		ps, ls = er.expected()
+		ls = append([]string(nil), ls...)
+	}
+
+	if t, ok := r.Peek(ps - r.Position()); ok {
+		return parser.NewSyntaxError(t, ps, ls...)
+	}
+
+	return parser.NewSyntaxError(nil, ps, ls...)
+}
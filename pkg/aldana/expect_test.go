@@ -0,0 +1,112 @@
+package aldana
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type spannedToken struct {
+	Value string
+	Line  int
+}
+
+func (t *spannedToken) Text() string {
+	return t.Value
+}
+
+func (t *spannedToken) GetSpan() lexer.Span {
+	return lexer.Span{Start: lexer.Position{Line: t.Line, Column: 1}}
+}
+
+func isValue(v string) TokenPredicate[*spannedToken] {
+	return func(t *spannedToken) bool {
+		return t.Value == v
+	}
+}
+
+func setUpSpannedReader(v ...string) parser.Reader[*spannedToken] {
+	var tks []*spannedToken
+	for i, s := range v {
+		tks = append(tks, &spannedToken{Value: s, Line: i})
+	}
+	rdr := NewReader(tks)
+	rdr.Next()
+	return rdr
+}
+
+/*
+Given: a reader and a predicate satisfied by the current token.
+When: expects and accepts the token.
+Then: returns the token and advances the reader.
+*/
+func TestExpect_with_satisfied_predicate(t *testing.T) {
+	// arrange
+	rdr := setUpSpannedReader("let", "x")
+
+	// act
+	tk, err := Expect(rdr, isValue("let"), "'let'")
+	tkX, ok := Accept(rdr, isValue("x"), "'x'")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "let", tk.Value)
+	assert.True(t, ok)
+	assert.Equal(t, "x", tkX.Value)
+	assert.Equal(t, 2, rdr.Position())
+}
+
+/*
+Given: a reader and predicates not satisfied by the tokens.
+When: accepts and expects the tokens.
+Then: returns a syntax error with all the labels expected at the furthest position.
+*/
+func TestExpect_with_unsatisfied_predicate(t *testing.T) {
+	t.Run("many labels", func(t *testing.T) {
+		// arrange
+		rdr := setUpSpannedReader("let", "=")
+
+		// act
+		_, _ = Expect(rdr, isValue("let"), "'let'")
+		_, ok := Accept(rdr, isValue("("), "'('")
+		_, err := Expect(rdr, isValue("id"), "identifier")
+
+		// assert
+		assert.False(t, ok)
+		assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+		assert.EqualError(t, err, "expected '(' or identifier but found '=' at 1:1")
+	})
+
+	t.Run("furthest position", func(t *testing.T) {
+		// arrange
+		rdr := setUpSpannedReader("a", "b", "c")
+
+		// act
+		m := rdr.Mark()
+		_, _ = Expect(rdr, isValue("a"), "'a'")
+		_, _ = Expect(rdr, isValue("x"), "'x'")
+		rdr.Reset(m)
+		_, err := Expect(rdr, isValue("y"), "'y'")
+
+		// assert
+		var sErr *parser.SyntaxError
+		assert.ErrorAs(t, err, &sErr)
+		assert.Equal(t, []string{"'x'"}, sErr.Expected)
+		assert.Equal(t, 1, sErr.Position)
+		assert.Equal(t, "'b'", sErr.Found)
+	})
+
+	t.Run("end of tokens", func(t *testing.T) {
+		// arrange
+		rdr := setUpSpannedReader("a")
+
+		// act
+		_, _ = Expect(rdr, isValue("a"), "'a'")
+		_, err := Expect(rdr, isValue(";"), "';'")
+
+		// assert
+		assert.EqualError(t, err, "expected ';' but found the end of the tokens")
+	})
+}
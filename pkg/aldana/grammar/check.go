@@ -0,0 +1,104 @@
+package grammar
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+)
+
+// CheckError is a problem of a production found by Check.
+type CheckError struct {
+	// Production is the name of the production with the problem.
+	Production string
+	// Position is where the production is declared.
+	Position lexer.Position
+	// Name is the name of the production that is undefined or unreachable.
+	Name string
+	// Err is ErrUndefinedProduction or ErrUnreachableProduction.
+	Err error
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("%s at %s: %s: %s", e.Production, e.Position, e.Err, e.Name)
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// refs calls f with the name of every Ref of the expression e, in order.
+func refs(e Expr, f func(n string)) {
+	switch e := e.(type) {
+	case *Ref:
+		f(e.Name)
+	case *Sequence:
+		for _, it := range e.Items {
+			refs(it, f)
+		}
+	case *Choice:
+		for _, a := range e.Alts {
+			refs(a, f)
+		}
+	case *Repeat:
+		refs(e.Expr, f)
+	}
+}
+
+// Check returns the problems of the grammar g joined, or nil when there is none. Where s is the start production,
+// or the first one when is empty.
+//
+// # About the problems
+//   - A production refers to a production that is not declared: ErrUndefinedProduction.
+//   - A production cannot be reached from the start production: ErrUnreachableProduction.
+func Check(g *Grammar, s string) error {
+	if s == "" {
+		s = g.Start()
+	}
+
+	var errs []error
+
+	if g.Production(s) == nil {
+		errs = append(errs, &CheckError{Production: s, Name: s, Err: ErrUndefinedProduction})
+	}
+
+	for _, p := range g.Productions {
+		seen := map[string]bool{}
+
+		refs(p.Expr, func(n string) {
+			if g.Production(n) != nil || seen[n] {
+				return
+			}
+			seen[n] = true
+			errs = append(errs, &CheckError{Production: p.Name, Position: p.Position, Name: n, Err: ErrUndefinedProduction})
+		})
+	}
+
+	reached := map[string]bool{}
+	pending := []string{s}
+
+	for len(pending) != 0 {
+		n := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if reached[n] {
+			continue
+		}
+
+		reached[n] = true
+
+		if p := g.Production(n); p != nil {
+			refs(p.Expr, func(n string) {
+				pending = append(pending, n)
+			})
+		}
+	}
+
+	for _, p := range g.Productions {
+		if !reached[p.Name] {
+			errs = append(errs, &CheckError{Production: p.Name, Position: p.Position, Name: p.Name, Err: ErrUnreachableProduction})
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package grammar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Given: a grammar with undefined and unreachable productions.
+When: checks the grammar.
+Then: returns a CheckError for each problem.
+*/
+func TestCheck(t *testing.T) {
+	// arrange
+	g, _ := Parse(`
+		program = decl { decl } ;
+		decl    = "let" IDENT "=" valeu ";" | stmt ;
+		value   = NUMBER ;
+	`)
+
+	// act
+	err := Check(g, "")
+
+	// assert
+	assert.ErrorIs(t, err, ErrUndefinedProduction)
+	assert.ErrorIs(t, err, ErrUnreachableProduction)
+	assert.EqualError(t, err, `decl at 2:32: the production is not declared: valeu
+decl at 2:32: the production is not declared: stmt
+value at 3:79: the production is not reachable from the start: value`)
+}
+
+/*
+Given: a grammar without problems.
+When: checks the grammar.
+Then: returns no error.
+*/
+func TestCheck_with_valid_grammar(t *testing.T) {
+	// arrange
+	g, _ := Parse(`program = decl { decl } ; decl = "let" IDENT ";" | program ;`)
+
+	// act
+	err := Check(g, "")
+
+	// assert
+	assert.NoError(t, err)
+}
@@ -0,0 +1,12 @@
+package grammar
+
+import "errors"
+
+var (
+	ErrEmptyGrammar          = errors.New("the grammar has no productions")
+	ErrUnterminatedLiteral   = errors.New("the literal is not terminated")
+	ErrDuplicatedProduction  = errors.New("the production is already declared")
+	ErrUndefinedProduction   = errors.New("the production is not declared")
+	ErrUnreachableProduction = errors.New("the production is not reachable from the start")
+	ErrUnknownExpression     = errors.New("the expression is not known")
+)
@@ -0,0 +1,339 @@
+package grammar
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GenerateOptions contains the options for generate the Go source of a parser.
+type GenerateOptions struct {
+	// Package is the name of the package of the source.
+	Package string
+	// Prefix is placed into the names of the declarations: <Prefix>Callbacks and New<Prefix>Parser.
+	Prefix string
+	// Start is the name of the root production. When is empty, the first production is the root.
+	Start string
+}
+
+// helpers are the names of the methods that the generated parser always declares.
+var helpers = []string{"matchLiteral", "matchKind", "matchRule", "backtrack", "alternate"}
+
+// reserved are the names that the methods cannot take: the Go keywords and the fields of the generated parser.
+var reserved = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto",
+	"if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+	"cb",
+}
+
+// generator writes the Go source of the parser of a grammar.
+type generator struct {
+	g   *Grammar
+	ops *GenerateOptions
+	b   bytes.Buffer
+	// recv is the receiver of the methods.
+	recv string
+	// names are the names of the declared methods.
+	names map[string]bool
+	// pending are the fragments of the expressions that are not written yet.
+	pending []pendingFragment
+}
+
+// pendingFragment is an expression whose method has to be written.
+type pendingFragment struct {
+	name string
+	base string
+	e    Expr
+}
+
+func (gn *generator) printf(f string, a ...any) {
+	fmt.Fprintf(&gn.b, f, a...)
+}
+
+// camel returns the name n of a production in camel-case, such as arrowFunction for arrow-function.
+func camel(n string) string {
+	var b strings.Builder
+
+	up := false
+
+	for i := 0; i < len(n); i++ {
+		switch c := n[i]; {
+		case c == '-' || c == '_':
+			up = b.Len() != 0
+		case up && c >= 'a' && c <= 'z':
+			b.WriteByte(c - 'a' + 'A')
+			up = false
+		case b.Len() == 0 && c >= 'A' && c <= 'Z':
+			b.WriteByte(c - 'A' + 'a')
+		default:
+			b.WriteByte(c)
+			up = false
+		}
+	}
+
+	if b.Len() == 0 {
+		return "production"
+	}
+
+	return b.String()
+}
+
+// unique returns the name n, with a numeric suffix when it is already declared, and declares it.
+func (gn *generator) unique(n string) string {
+	u := n
+	for i := 1; gn.names[u]; i++ {
+		u = n + strconv.Itoa(i)
+	}
+	gn.names[u] = true
+	return u
+}
+
+// step returns the call that matches the expression e and appends its nodes to the slice pointed by nds.
+// Where the expressions that are not terminals or references are written as fragment methods named by base.
+func (gn *generator) step(e Expr, base string, nds string) string {
+	switch e := e.(type) {
+	case *Literal:
+		return fmt.Sprintf("p.matchLiteral(r, %s, %s)", nds, strconv.Quote(e.Value))
+	case *Kind:
+		return fmt.Sprintf("p.matchKind(r, %s, %s)", nds, strconv.Quote(e.Name))
+	case *Ref:
+		return fmt.Sprintf("p.matchRule(r, f, %s, %s)", nds, strconv.Quote(e.Name))
+	}
+
+	m := gn.unique(base)
+	gn.pending = append(gn.pending, pendingFragment{name: m, base: base, e: e})
+
+	return fmt.Sprintf("p.%s(r, f, %s)", m, nds)
+}
+
+// fragment writes the method of the expression e, named n. Where its inner fragments are named by base.
+func (gn *generator) fragment(n string, base string, e Expr) {
+	gn.printf("// %s\n", e.String())
+	gn.printf("func (p *%s[Tt, Tn]) %s(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {\n", gn.recv, n)
+
+	switch e := e.(type) {
+	case *Sequence:
+		if len(e.Items) == 0 {
+			gn.printf("return nil\n}\n\n")
+			return
+		}
+
+		gn.printf("m, n := r.Mark(), len(*nds)\n\n")
+
+		for _, it := range e.Items {
+			gn.printf("if err := %s; err != nil {\nreturn p.backtrack(r, m, nds, n, err)\n}\n\n", gn.step(it, base, "nds"))
+		}
+
+		gn.printf("return nil\n")
+	case *Choice:
+		gn.printf("var fail error\n\n")
+
+		for _, a := range e.Alts {
+			gn.printf("if err := %s; !p.alternate(&fail, err) {\nreturn err\n}\n\n", gn.step(a, base, "nds"))
+		}
+
+		gn.printf("return fail\n")
+	case *Repeat:
+		s := gn.step(e.Expr, base, "nds")
+
+		if e.Max == 1 {
+			gn.printf("if err := %s; err != nil && !errors.Is(err, parser.ErrInvalidSyntax) {\nreturn err\n}\n\n", s)
+			gn.printf("return nil\n")
+			break
+		}
+
+		if e.Min == 0 {
+			gn.printf("for {\n")
+			gn.printf("s := r.Mark()\n\n")
+			gn.printf("if err := %s; err != nil {\n", s)
+			gn.printf("if !errors.Is(err, parser.ErrInvalidSyntax) {\nreturn err\n}\nreturn nil\n}\n\n")
+		} else {
+			gn.printf("for i := 0; ; i++ {\n")
+			gn.printf("s := r.Mark()\n\n")
+			gn.printf("if err := %s; err != nil {\n", s)
+			gn.printf("if i < %d || !errors.Is(err, parser.ErrInvalidSyntax) {\nreturn err\n}\nreturn nil\n}\n\n", e.Min)
+		}
+
+		gn.printf("if r.Position() == s {\nreturn nil\n}\n}\n")
+	}
+
+	gn.printf("}\n\n")
+}
+
+// production writes the rule of the production p and its fragments.
+func (gn *generator) production(p *Production, parse string) {
+	gn.printf("// %s\n", p.String())
+	gn.printf("func (p *%s[Tt, Tn]) %s(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {\n", gn.recv, parse)
+	gn.printf("var nds []Tn\n\n")
+	gn.printf("if err := %s; err != nil {\nreturn *new(Tn), err\n}\n\n", gn.step(p.Expr, camel(p.Name), "&nds"))
+	gn.printf("return p.cb.Node(%s, nds), nil\n}\n\n", strconv.Quote(p.Name))
+
+	for len(gn.pending) != 0 {
+		f := gn.pending[0]
+		gn.pending = gn.pending[1:]
+		gn.fragment(f.name, f.base, f.e)
+	}
+}
+
+// Generate writes to w the Go source of a recursive-descent parser for the grammar g.
+// The grammar is checked before, so its problems are returned instead.
+//
+// # About the source
+//   - New<Prefix>Parser returns a parser.Parser[Tt, Tn] made by aldana.NewParser, so the rules are named as the productions.
+//   - The tokens are matched, and the nodes are built, by the functions of <Prefix>Callbacks. There is no reflection.
+//   - The choices are ordered and backtrack, as the parsers of NewParser.
+//   - The source is formatted and its declarations follow the order of the productions, so the output is deterministic.
+//   - The methods are named by the productions, with a numeric suffix when the name is a Go keyword or a field of the parser,
+//     such as if1 for the production if.
+//
+// # Example
+//
+//	g, _ := Parse(src)
+//	err := Generate(f, g, &GenerateOptions{Package: "calc", Prefix: "Calc"})
+func Generate(w io.Writer, g *Grammar, ops *GenerateOptions) error {
+	if err := Check(g, ops.Start); err != nil {
+		return err
+	}
+
+	s := ops.Start
+
+	if s == "" {
+		s = g.Start()
+	}
+
+	gn := &generator{
+		g:     g,
+		ops:   ops,
+		recv:  camel(ops.Prefix) + "Parser",
+		names: map[string]bool{},
+	}
+
+	if ops.Prefix == "" {
+		gn.recv = "generatedParser"
+	}
+
+	for _, h := range helpers {
+		gn.names[h] = true
+	}
+
+	for _, n := range reserved {
+		gn.names[n] = true
+	}
+
+	cb := ops.Prefix + "Callbacks"
+
+	gn.printf("// Code generated by aldana gen parser. DO NOT EDIT.\n\n")
+	gn.printf("package %s\n\n", ops.Package)
+	gn.printf("import (\n\"errors\"\n\n")
+	gn.printf("\"github.com/agustin-del-pino/aldana/pkg/aldana\"\n")
+	gn.printf("\"github.com/agustin-del-pino/aldana/pkg/aldana/parser\"\n)\n\n")
+
+	gn.printf("// %s are the functions that match the tokens and build the nodes of the parser.\n", cb)
+	gn.printf("type %s[Tt any, Tn any] struct {\n", cb)
+	gn.printf("// Literal indicates whether the token t has the value v.\nLiteral func(t Tt, v string) bool\n")
+	gn.printf("// Kind indicates whether the token t is of the kind k.\nKind func(t Tt, k string) bool\n")
+	gn.printf("// Leaf returns the node of a matched token.\nLeaf func(t Tt) Tn\n")
+	gn.printf("// Node returns the node of the production named rule, made by the nodes of its matched terminals and non-terminals.\n")
+	gn.printf("Node func(rule string, nds []Tn) Tn\n}\n\n")
+
+	gn.printf("type %s[Tt any, Tn any] struct {\ncb *%s[Tt, Tn]\n}\n\n", gn.recv, cb)
+
+	parses := make([]string, len(g.Productions))
+
+	for i, p := range g.Productions {
+		c := camel(p.Name)
+		parses[i] = gn.unique("parse" + strings.ToUpper(c[:1]) + c[1:])
+	}
+
+	gn.printf("// New%sParser returns the parser of the grammar, that matches the tokens and builds the nodes by the callbacks cb.\n", ops.Prefix)
+	gn.printf("func New%sParser[Tt any, Tn any](cb *%s[Tt, Tn]) parser.Parser[Tt, Tn] {\n", ops.Prefix, cb)
+	gn.printf("p := &%s[Tt, Tn]{cb: cb}\n\n", gn.recv)
+	gn.printf("return aldana.NewParser(&aldana.ParserOptions[Tt, Tn]{\nParseRules: map[string]aldana.NodeRule[Tt, Tn]{\n")
+
+	for i, p := range g.Productions {
+		gn.printf("%s: p.%s,\n", strconv.Quote(p.Name), parses[i])
+	}
+
+	gn.printf("},\nRoot: %s,\n})\n}\n\n", strconv.Quote(s))
+
+	for i, p := range g.Productions {
+		gn.production(p, parses[i])
+	}
+
+	gn.printf(runtime, gn.recv)
+
+	src, err := format.Source(gn.b.Bytes())
+	if err != nil {
+		return fmt.Errorf("grammar: the generated source is invalid: %w", err)
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+// runtime are the helpers of the generated parser, where %[1]s is its receiver.
+const runtime = `// matchLiteral appends the leaf of the current token to nds when its value is v.
+func (p *%[1]s[Tt, Tn]) matchLiteral(r parser.Reader[Tt], nds *[]Tn, v string) error {
+	t, err := aldana.Expect(r, func(t Tt) bool {
+		return p.cb.Literal(t, v)
+	}, "'"+v+"'")
+	if err != nil {
+		return err
+	}
+
+	*nds = append(*nds, p.cb.Leaf(t))
+	return nil
+}
+
+// matchKind appends the leaf of the current token to nds when its kind is k.
+func (p *%[1]s[Tt, Tn]) matchKind(r parser.Reader[Tt], nds *[]Tn, k string) error {
+	t, err := aldana.Expect(r, func(t Tt) bool {
+		return p.cb.Kind(t, k)
+	}, k)
+	if err != nil {
+		return err
+	}
+
+	*nds = append(*nds, p.cb.Leaf(t))
+	return nil
+}
+
+// matchRule appends the node of the rule n to nds. When the rule fails, the reader is reset.
+func (p *%[1]s[Tt, Tn]) matchRule(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn, n string) error {
+	m := r.Mark()
+
+	nd, err := f(n, r)
+	if err != nil {
+		r.Reset(m)
+		return err
+	}
+
+	*nds = append(*nds, nd)
+	return nil
+}
+
+// backtrack resets the reader r to the mark m and the nodes nds to the length n, and returns err.
+func (p *%[1]s[Tt, Tn]) backtrack(r parser.Reader[Tt], m int, nds *[]Tn, n int, err error) error {
+	r.Reset(m)
+	*nds = (*nds)[:n]
+	return err
+}
+
+// alternate indicates whether the next alternative has to be tried after err, and keeps the furthest syntax failure in fail.
+func (p *%[1]s[Tt, Tn]) alternate(fail *error, err error) bool {
+	if err == nil || !errors.Is(err, parser.ErrInvalidSyntax) {
+		return false
+	}
+
+	var sf, se *parser.SyntaxError
+
+	if *fail == nil || !errors.As(*fail, &sf) || !errors.As(err, &se) || se.Position >= sf.Position {
+		*fail = err
+	}
+
+	return true
+}
+`
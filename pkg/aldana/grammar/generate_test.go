@@ -0,0 +1,92 @@
+package grammar
+
+import (
+	"bytes"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Given: the grammar of the calc package.
+When: generates the source of its parser.
+Then: returns the same source as the generated parser of the package.
+*/
+func TestGenerate(t *testing.T) {
+	// arrange
+	src, err := os.ReadFile("internal/calc/calc.ebnf")
+	assert.NoError(t, err)
+	expect, err := os.ReadFile("internal/calc/parser.go")
+	assert.NoError(t, err)
+	g, err := Parse(string(src))
+	assert.NoError(t, err)
+
+	// act
+	var b bytes.Buffer
+	err = Generate(&b, g, &GenerateOptions{Package: "calc", Prefix: "Calc"})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, string(expect), b.String())
+}
+
+/*
+Given: a grammar with an undefined production.
+When: generates the source of its parser.
+Then: returns ErrUndefinedProduction and writes nothing.
+*/
+func TestGenerate_with_invalid_grammar(t *testing.T) {
+	// arrange
+	g, _ := Parse(`program = decl+ ;`)
+
+	// act
+	var b bytes.Buffer
+	err := Generate(&b, g, &GenerateOptions{Package: "calc"})
+
+	// assert
+	assert.ErrorIs(t, err, ErrUndefinedProduction)
+	assert.Zero(t, b.Len())
+}
+
+/*
+Given: grammars whose productions are named as a Go keyword and as the field of the generated parser.
+When: generates the source of their parsers.
+Then: returns valid sources, whose methods are unique and are not named as the field.
+*/
+func TestGenerate_with_reserved_names(t *testing.T) {
+	cases := map[string]string{
+		"keyword": `if = "x" | "y" ;`,
+		"field":   `cb = "x" | "y" ;`,
+	}
+
+	for name, src := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			g, err := Parse(src)
+			assert.NoError(t, err)
+
+			// act
+			var b bytes.Buffer
+			err = Generate(&b, g, &GenerateOptions{Package: "calc"})
+
+			// assert
+			assert.NoError(t, err)
+
+			f, err := goparser.ParseFile(token.NewFileSet(), "parser.go", b.Bytes(), 0)
+			assert.NoError(t, err)
+
+			methods := map[string]bool{}
+			for _, d := range f.Decls {
+				if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv != nil {
+					assert.False(t, methods[fd.Name.Name], fd.Name.Name)
+					methods[fd.Name.Name] = true
+				}
+			}
+			assert.False(t, methods["cb"])
+		})
+	}
+}
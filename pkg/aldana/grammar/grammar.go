@@ -0,0 +1,155 @@
+package grammar
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+)
+
+// Expr is an expression of the right side of a production.
+type Expr interface {
+	// String returns the expression in the grammar notation.
+	String() string
+}
+
+// Choice is an ordered choice between expressions: a | b. The first alternative that matches is taken.
+type Choice struct {
+	Alts []Expr
+}
+
+func (e *Choice) String() string {
+	s := make([]string, len(e.Alts))
+	for i, a := range e.Alts {
+		s[i] = a.String()
+	}
+	return strings.Join(s, " | ")
+}
+
+// Sequence is a sequence of expressions: a b c. The empty sequence matches no tokens.
+type Sequence struct {
+	Items []Expr
+}
+
+func (e *Sequence) String() string {
+	s := make([]string, len(e.Items))
+	for i, it := range e.Items {
+		if _, ok := it.(*Choice); ok {
+			s[i] = "(" + it.String() + ")"
+		} else {
+			s[i] = it.String()
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// Repeat is a repetition of an expression between Min and Max times. Where a Max of -1 is unbounded.
+//   - {a} and a* are repeated from 0 to -1.
+//   - a+ is repeated from 1 to -1.
+//   - [a] and a? are repeated from 0 to 1.
+type Repeat struct {
+	Expr Expr
+	Min  int
+	Max  int
+}
+
+func (e *Repeat) String() string {
+	switch {
+	case e.Min == 0 && e.Max == 1:
+		return "[" + e.Expr.String() + "]"
+	case e.Min == 0:
+		return "{" + e.Expr.String() + "}"
+	}
+
+	switch e.Expr.(type) {
+	case *Choice, *Sequence:
+		return "(" + e.Expr.String() + ")+"
+	}
+
+	return e.Expr.String() + "+"
+}
+
+// Literal is a terminal that matches the tokens by their value: "let".
+type Literal struct {
+	Value string
+}
+
+func (e *Literal) String() string {
+	return strconv.Quote(e.Value)
+}
+
+// Kind is a terminal that matches the tokens by their kind: IDENT. Its name has no lowercase letters.
+type Kind struct {
+	Name string
+}
+
+func (e *Kind) String() string {
+	return e.Name
+}
+
+// Ref is a non-terminal that matches the production of its name: value.
+type Ref struct {
+	Name string
+}
+
+func (e *Ref) String() string {
+	return e.Name
+}
+
+// Production is a named rule of the grammar: name = expr ;
+type Production struct {
+	Name string
+	Expr Expr
+	// Position is where the production is declared.
+	Position lexer.Position
+}
+
+func (p *Production) String() string {
+	return p.Name + " = " + p.Expr.String() + " ;"
+}
+
+// Grammar is an ordered list of productions. Where the first one is the start production.
+type Grammar struct {
+	Productions []*Production
+}
+
+// Start returns the name of the first production, or empty when there is none.
+func (g *Grammar) Start() string {
+	if len(g.Productions) == 0 {
+		return ""
+	}
+	return g.Productions[0].Name
+}
+
+// Production returns the production named n, or nil when it does not exist.
+func (g *Grammar) Production(n string) *Production {
+	for _, p := range g.Productions {
+		if p.Name == n {
+			return p
+		}
+	}
+	return nil
+}
+
+// String returns the grammar in its notation, one production per line.
+func (g *Grammar) String() string {
+	s := make([]string, len(g.Productions))
+	for i, p := range g.Productions {
+		s[i] = p.String()
+	}
+	return strings.Join(s, "\n")
+}
+
+// isKindName returns a boolean that indicates whether the identifier n is the name of a Kind.
+func isKindName(n string) bool {
+	hasUpper := false
+	for i := 0; i < len(n); i++ {
+		switch b := n[i]; {
+		case b >= 'a' && b <= 'z':
+			return false
+		case b >= 'A' && b <= 'Z':
+			hasUpper = true
+		}
+	}
+	return hasUpper
+}
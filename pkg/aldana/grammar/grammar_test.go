@@ -0,0 +1,63 @@
+package grammar
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Given: a grammar text.
+When: parses the text.
+Then: returns the Grammar with its productions.
+*/
+func TestParse(t *testing.T) {
+	// arrange
+	src := `
+		# declarations
+		program = { decl } ;
+		decl    = "let" IDENT ('=' | ":=") value ";" ;
+		value   = NUMBER | IDENT | "(" value+ ")" | [ "-" ] value? ;
+	`
+
+	// act
+	g, err := Parse(src)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "program", g.Start())
+	assert.Equal(t, lexer.Position{Line: 3, Column: 44}, g.Production("decl").Position)
+	assert.Equal(t, &Kind{Name: "IDENT"}, g.Production("decl").Expr.(*Sequence).Items[1])
+	assert.Equal(t, `program = {decl} ;
+decl = "let" IDENT ("=" | ":=") value ";" ;
+value = NUMBER | IDENT | "(" value+ ")" | ["-"] [value] ;`, g.String())
+}
+
+/*
+Given: invalid grammar texts.
+When: parses the texts.
+Then: returns an error.
+*/
+func TestParse_with_invalid_grammar(t *testing.T) {
+	cases := map[string]struct {
+		src    string
+		expect error
+	}{
+		"empty":                 {" # nothing\n", ErrEmptyGrammar},
+		"unterminated literal":  {`a = "b ;`, ErrUnterminatedLiteral},
+		"duplicated production": {`a = "b" ; a = "c" ;`, ErrDuplicatedProduction},
+		"missing semicolon":     {`a = "b" c = "d" ;`, parser.ErrInvalidSyntax},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// act
+			_, err := Parse(c.src)
+
+			// assert
+			assert.ErrorIs(t, err, c.expect)
+		})
+	}
+}
@@ -0,0 +1,340 @@
+// Code generated by aldana gen parser. DO NOT EDIT.
+
+package calc
+
+import (
+	"errors"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// CalcCallbacks are the functions that match the tokens and build the nodes of the parser.
+type CalcCallbacks[Tt any, Tn any] struct {
+	// Literal indicates whether the token t has the value v.
+	Literal func(t Tt, v string) bool
+	// Kind indicates whether the token t is of the kind k.
+	Kind func(t Tt, k string) bool
+	// Leaf returns the node of a matched token.
+	Leaf func(t Tt) Tn
+	// Node returns the node of the production named rule, made by the nodes of its matched terminals and non-terminals.
+	Node func(rule string, nds []Tn) Tn
+}
+
+type calcParser[Tt any, Tn any] struct {
+	cb *CalcCallbacks[Tt, Tn]
+}
+
+// NewCalcParser returns the parser of the grammar, that matches the tokens and builds the nodes by the callbacks cb.
+func NewCalcParser[Tt any, Tn any](cb *CalcCallbacks[Tt, Tn]) parser.Parser[Tt, Tn] {
+	p := &calcParser[Tt, Tn]{cb: cb}
+
+	return aldana.NewParser(&aldana.ParserOptions[Tt, Tn]{
+		ParseRules: map[string]aldana.NodeRule[Tt, Tn]{
+			"program": p.parseProgram,
+			"decl":    p.parseDecl,
+			"value":   p.parseValue,
+			"call":    p.parseCall,
+		},
+		Root: "program",
+	})
+}
+
+// program = decl+ ;
+func (p *calcParser[Tt, Tn]) parseProgram(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	var nds []Tn
+
+	if err := p.program(r, f, &nds); err != nil {
+		return *new(Tn), err
+	}
+
+	return p.cb.Node("program", nds), nil
+}
+
+// decl+
+func (p *calcParser[Tt, Tn]) program(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	for i := 0; ; i++ {
+		s := r.Mark()
+
+		if err := p.matchRule(r, f, nds, "decl"); err != nil {
+			if i < 1 || !errors.Is(err, parser.ErrInvalidSyntax) {
+				return err
+			}
+			return nil
+		}
+
+		if r.Position() == s {
+			return nil
+		}
+	}
+}
+
+// decl = "let" IDENT "=" value ";" ;
+func (p *calcParser[Tt, Tn]) parseDecl(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	var nds []Tn
+
+	if err := p.decl(r, f, &nds); err != nil {
+		return *new(Tn), err
+	}
+
+	return p.cb.Node("decl", nds), nil
+}
+
+// "let" IDENT "=" value ";"
+func (p *calcParser[Tt, Tn]) decl(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchLiteral(r, nds, "let"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchKind(r, nds, "IDENT"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchLiteral(r, nds, "="); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchRule(r, f, nds, "value"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchLiteral(r, nds, ";"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// value = NUMBER | IDENT | "[" [value {"," value}] "]" | call ;
+func (p *calcParser[Tt, Tn]) parseValue(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	var nds []Tn
+
+	if err := p.value(r, f, &nds); err != nil {
+		return *new(Tn), err
+	}
+
+	return p.cb.Node("value", nds), nil
+}
+
+// NUMBER | IDENT | "[" [value {"," value}] "]" | call
+func (p *calcParser[Tt, Tn]) value(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	var fail error
+
+	if err := p.matchKind(r, nds, "NUMBER"); !p.alternate(&fail, err) {
+		return err
+	}
+
+	if err := p.matchKind(r, nds, "IDENT"); !p.alternate(&fail, err) {
+		return err
+	}
+
+	if err := p.value1(r, f, nds); !p.alternate(&fail, err) {
+		return err
+	}
+
+	if err := p.matchRule(r, f, nds, "call"); !p.alternate(&fail, err) {
+		return err
+	}
+
+	return fail
+}
+
+// "[" [value {"," value}] "]"
+func (p *calcParser[Tt, Tn]) value1(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchLiteral(r, nds, "["); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.value2(r, f, nds); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchLiteral(r, nds, "]"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// [value {"," value}]
+func (p *calcParser[Tt, Tn]) value2(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	if err := p.value3(r, f, nds); err != nil && !errors.Is(err, parser.ErrInvalidSyntax) {
+		return err
+	}
+
+	return nil
+}
+
+// value {"," value}
+func (p *calcParser[Tt, Tn]) value3(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchRule(r, f, nds, "value"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.value4(r, f, nds); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// {"," value}
+func (p *calcParser[Tt, Tn]) value4(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	for {
+		s := r.Mark()
+
+		if err := p.value5(r, f, nds); err != nil {
+			if !errors.Is(err, parser.ErrInvalidSyntax) {
+				return err
+			}
+			return nil
+		}
+
+		if r.Position() == s {
+			return nil
+		}
+	}
+}
+
+// "," value
+func (p *calcParser[Tt, Tn]) value5(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchLiteral(r, nds, ","); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchRule(r, f, nds, "value"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// call = "@" IDENT ("(" ")" | value) ;
+func (p *calcParser[Tt, Tn]) parseCall(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	var nds []Tn
+
+	if err := p.call(r, f, &nds); err != nil {
+		return *new(Tn), err
+	}
+
+	return p.cb.Node("call", nds), nil
+}
+
+// "@" IDENT ("(" ")" | value)
+func (p *calcParser[Tt, Tn]) call(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchLiteral(r, nds, "@"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchKind(r, nds, "IDENT"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.call1(r, f, nds); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// "(" ")" | value
+func (p *calcParser[Tt, Tn]) call1(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	var fail error
+
+	if err := p.call2(r, f, nds); !p.alternate(&fail, err) {
+		return err
+	}
+
+	if err := p.matchRule(r, f, nds, "value"); !p.alternate(&fail, err) {
+		return err
+	}
+
+	return fail
+}
+
+// "(" ")"
+func (p *calcParser[Tt, Tn]) call2(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn) error {
+	m, n := r.Mark(), len(*nds)
+
+	if err := p.matchLiteral(r, nds, "("); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	if err := p.matchLiteral(r, nds, ")"); err != nil {
+		return p.backtrack(r, m, nds, n, err)
+	}
+
+	return nil
+}
+
+// matchLiteral appends the leaf of the current token to nds when its value is v.
+func (p *calcParser[Tt, Tn]) matchLiteral(r parser.Reader[Tt], nds *[]Tn, v string) error {
+	t, err := aldana.Expect(r, func(t Tt) bool {
+		return p.cb.Literal(t, v)
+	}, "'"+v+"'")
+	if err != nil {
+		return err
+	}
+
+	*nds = append(*nds, p.cb.Leaf(t))
+	return nil
+}
+
+// matchKind appends the leaf of the current token to nds when its kind is k.
+func (p *calcParser[Tt, Tn]) matchKind(r parser.Reader[Tt], nds *[]Tn, k string) error {
+	t, err := aldana.Expect(r, func(t Tt) bool {
+		return p.cb.Kind(t, k)
+	}, k)
+	if err != nil {
+		return err
+	}
+
+	*nds = append(*nds, p.cb.Leaf(t))
+	return nil
+}
+
+// matchRule appends the node of the rule n to nds. When the rule fails, the reader is reset.
+func (p *calcParser[Tt, Tn]) matchRule(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn], nds *[]Tn, n string) error {
+	m := r.Mark()
+
+	nd, err := f(n, r)
+	if err != nil {
+		r.Reset(m)
+		return err
+	}
+
+	*nds = append(*nds, nd)
+	return nil
+}
+
+// backtrack resets the reader r to the mark m and the nodes nds to the length n, and returns err.
+func (p *calcParser[Tt, Tn]) backtrack(r parser.Reader[Tt], m int, nds *[]Tn, n int, err error) error {
+	r.Reset(m)
+	*nds = (*nds)[:n]
+	return err
+}
+
+// alternate indicates whether the next alternative has to be tried after err, and keeps the furthest syntax failure in fail.
+func (p *calcParser[Tt, Tn]) alternate(fail *error, err error) bool {
+	if err == nil || !errors.Is(err, parser.ErrInvalidSyntax) {
+		return false
+	}
+
+	var sf, se *parser.SyntaxError
+
+	if *fail == nil || !errors.As(*fail, &sf) || !errors.As(err, &se) || se.Position >= sf.Position {
+		*fail = err
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func isKind(t string, k string) bool {
+	switch k {
+	case "NUMBER":
+		return strings.Trim(t, "0123456789") == ""
+	case "IDENT":
+		return strings.Trim(t, "abcdefghijklmnopqrstuvwxyz") == ""
+	}
+	return false
+}
+
+func setUpParsers(t *testing.T) (parser.Parser[string, *grammar.Tree[string]], parser.Parser[string, *grammar.Tree[string]]) {
+	ops := grammar.TreeOptions(func(v string) aldana.TokenPredicate[string] {
+		return func(t string) bool {
+			return t == v
+		}
+	}, func(k string) aldana.TokenPredicate[string] {
+		return func(t string) bool {
+			return isKind(t, k)
+		}
+	})
+
+	gen := NewCalcParser(&CalcCallbacks[string, *grammar.Tree[string]]{
+		Literal: func(t string, v string) bool {
+			return t == v
+		},
+		Kind: isKind,
+		Leaf: ops.Leaf,
+		Node: ops.Node,
+	})
+
+	src := `
+		program = decl+ ;
+		decl    = "let" IDENT "=" value ";" ;
+		value   = NUMBER | IDENT | "[" [ value { "," value } ] "]" | call ;
+		call    = "@" IDENT ( "(" ")" | value ) ;
+	`
+	g, err := grammar.Parse(src)
+	assert.NoError(t, err)
+
+	prs, err := grammar.NewParser(g, ops)
+	assert.NoError(t, err)
+
+	return gen, prs
+}
+
+/*
+Given: the generated parser and the runtime parser of the same grammar.
+When: parses the same tokens.
+Then: returns the same trees and errors.
+*/
+func TestNewCalcParser(t *testing.T) {
+	cases := map[string][]string{
+		"declarations":   {"let", "a", "=", "[", "1", ",", "b", "]", ";", "let", "b", "=", "@", "f", "(", ")", ";"},
+		"call of value":  {"let", "a", "=", "@", "f", "2", ";"},
+		"empty list":     {"let", "a", "=", "[", "]", ";"},
+		"missing value":  {"let", "a", "=", ";"},
+		"missing comma":  {"let", "a", "=", "[", "1", "2", "]", ";"},
+		"missing parens": {"let", "a", "=", "@", "f", "(", ";"},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// arrange
+			gen, run := setUpParsers(t)
+
+			// act
+			gtr, gerr := gen.Parse(aldana.NewReader(c))
+			rtr, rerr := run.Parse(aldana.NewReader(c))
+
+			// assert
+			assert.Equal(t, rerr, gerr)
+			assert.Equal(t, rtr, gtr)
+		})
+	}
+}
+
+/*
+Given: the generated parser.
+When: parses acceptable tokens, non acceptable tokens and tokens with a trailing token.
+Then: returns the expected trees, otherwise the expected errors, where the trailing token is unhandled.
+*/
+func TestNewCalcParser_expectations(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		tree   string
+		err    string
+	}{
+		"list":           {tokens: []string{"let", "a", "=", "[", "1", ",", "b", "]", ";"}, tree: "(program (decl let a = (value [ (value 1) , (value b) ]) ;))"},
+		"call of value":  {tokens: []string{"let", "a", "=", "@", "f", "2", ";"}, tree: "(program (decl let a = (value (call @ f (value 2))) ;))"},
+		"missing value":  {tokens: []string{"let", "a", "=", ";"}, err: "program > decl > value > call: expected NUMBER, IDENT, '[' or '@' but found ;"},
+		"trailing token": {tokens: []string{"let", "a", "=", "1", ";", "2"}, err: parser.ErrUnhandledToken.Error()},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// arrange
+			gen, _ := setUpParsers(t)
+
+			// act
+			tr, err := gen.Parse(aldana.NewReader(c.tokens))
+
+			// assert
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.tree, tr.String())
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/token"
+)
+
+// kind is the kind of the tokens of the grammar notation.
+type kind int
+
+const (
+	identKind kind = iota
+	literalKind
+	symbolKind
+)
+
+func (k kind) String() string {
+	switch k {
+	case identKind:
+		return "identifier"
+	case literalKind:
+		return "literal"
+	default:
+		return "symbol"
+	}
+}
+
+type gToken = token.Token[kind]
+
+var (
+	identStart = ranges.RangeByteOfRange(ranges.ByteBounded('a', 'z'), ranges.ByteBounded('A', 'Z'), ranges.ByteSingle('_'))
+	identPart  = ranges.RangeByteOfRange(identStart, ranges.ByteBounded('0', '9'), ranges.ByteSingle('-'))
+	spaces     = ranges.ByteSet(' ', '\t', '\r', '\n')
+	quotes     = ranges.ByteSet('"', '\'')
+	symbols    = ranges.ByteSet('=', ';', '|', '(', ')', '[', ']', '{', '}', '*', '+', '?')
+)
+
+func lexComment(c lexer.Cursor, r ranges.ByteRange) (*gToken, bool, error) {
+	for c.HasChar() && c.GetChar() != '\n' {
+		c.Next()
+	}
+	return nil, false, nil
+}
+
+func lexIdent(c lexer.Cursor, r ranges.ByteRange) *gToken {
+	return token.Read(c, identKind, func(c lexer.Cursor) {
+		for c.HasChar() && identPart(c.GetChar()) {
+			c.Next()
+		}
+	})
+}
+
+func lexLiteral(c lexer.Cursor, r ranges.ByteRange) (*gToken, bool, error) {
+	q := c.GetChar()
+	closed := false
+
+	t := token.Read(c, literalKind, func(c lexer.Cursor) {
+		c.Next()
+		for c.HasChar() && c.GetChar() != q && c.GetChar() != '\n' {
+			if c.GetChar() == '\\' {
+				c.Next()
+			}
+			c.Next()
+		}
+		if closed = c.HasChar() && c.GetChar() == q; closed {
+			c.Next()
+		}
+	})
+
+	if !closed {
+		return nil, false, ErrUnterminatedLiteral
+	}
+
+	v, err := strconv.Unquote(doubleQuoted(t.Raw))
+	if err != nil {
+		return nil, false, err
+	}
+	t.Value = v
+
+	return t, true, nil
+}
+
+// doubleQuoted returns the quoted bytes q with double quotes, as expected by strconv.Unquote.
+func doubleQuoted(q []byte) string {
+	if q[0] == '"' {
+		return string(q)
+	}
+
+	b := []byte{'"'}
+
+	for i := 1; i < len(q)-1; i++ {
+		switch {
+		case q[i] == '\\' && q[i+1] == '\'':
+			b = append(b, '\'')
+			i++
+		case q[i] == '\\':
+			b = append(b, q[i], q[i+1])
+			i++
+		case q[i] == '"':
+			b = append(b, '\\', '"')
+		default:
+			b = append(b, q[i])
+		}
+	}
+
+	return string(append(b, '"'))
+}
+
+func lexSymbol(c lexer.Cursor, r ranges.ByteRange) *gToken {
+	return token.Read(c, symbolKind, func(c lexer.Cursor) {
+		c.Next()
+	})
+}
+
+func ignoreSpaces() aldana.LexicalOmit {
+	return func() (ranges.ByteRange, func(c lexer.Cursor, r ranges.ByteRange)) {
+		return spaces, func(c lexer.Cursor, r ranges.ByteRange) {
+			if c.GetChar() == '\n' {
+				c.AddLine(1)
+			}
+			c.Next()
+		}
+	}
+}
+
+var grammarLexer = aldana.NewLexer(&aldana.LexerOptions[*gToken]{
+	Ignore: ignoreSpaces(),
+	LexRules: []aldana.LexicalRule[*gToken]{
+		aldana.NewFallibleLexicalRule(ranges.ByteSingle('#'), lexComment),
+		aldana.NewLexicalRule(identStart, lexIdent),
+		aldana.NewFallibleLexicalRule(quotes, lexLiteral),
+		aldana.NewLexicalRule(symbols, lexSymbol),
+	},
+})
+
+func isSymbol(s string) aldana.TokenPredicate[*gToken] {
+	return func(t *gToken) bool {
+		return t.Is(symbolKind) && t.IsRaw(s)
+	}
+}
+
+func isIdent(t *gToken) bool {
+	return t.Is(identKind)
+}
+
+// startsPrimary returns a boolean that indicates whether the token t is the start of a primary expression.
+func startsPrimary(t *gToken) bool {
+	return t.Is(identKind) || t.Is(literalKind) || isSymbol("(")(t) || isSymbol("[")(t) || isSymbol("{")(t)
+}
+
+func parseGrammar(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	g := &Grammar{}
+
+	for {
+		if _, ok := r.Peek(0); !ok {
+			return g, nil
+		}
+
+		t, _ := r.Peek(0)
+
+		nd, err := f("production", r)
+		if err != nil {
+			return nil, err
+		}
+
+		p := nd.(*Production)
+
+		if g.Production(p.Name) != nil {
+			return nil, aldana.GetTokenError(ErrDuplicatedProduction, t)
+		}
+
+		g.Productions = append(g.Productions, p)
+	}
+}
+
+func parseProduction(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	n, err := aldana.Expect(r, isIdent, "production name")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := aldana.Expect(r, isSymbol("="), "'='"); err != nil {
+		return nil, err
+	}
+
+	e, err := f("choice", r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := aldana.Expect(r, isSymbol(";"), "';'"); err != nil {
+		return nil, err
+	}
+
+	return &Production{Name: n.Text(), Expr: e.(Expr), Position: n.Start}, nil
+}
+
+func parseChoice(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	var alts []Expr
+
+	for {
+		e, err := f("sequence", r)
+		if err != nil {
+			return nil, err
+		}
+
+		alts = append(alts, e.(Expr))
+
+		if _, ok := aldana.Accept(r, isSymbol("|"), "'|'"); !ok {
+			break
+		}
+	}
+
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+
+	return &Choice{Alts: alts}, nil
+}
+
+func parseSequence(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	var items []Expr
+
+	for {
+		if t, ok := r.Peek(0); !ok || !startsPrimary(t) {
+			break
+		}
+
+		e, err := f("postfix", r)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e.(Expr))
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return &Sequence{Items: items}, nil
+}
+
+func parsePostfix(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	nd, err := f("primary", r)
+	if err != nil {
+		return nil, err
+	}
+
+	e := nd.(Expr)
+
+	switch {
+	case acceptSymbol(r, "*"):
+		return &Repeat{Expr: e, Min: 0, Max: -1}, nil
+	case acceptSymbol(r, "+"):
+		return &Repeat{Expr: e, Min: 1, Max: -1}, nil
+	case acceptSymbol(r, "?"):
+		return &Repeat{Expr: e, Min: 0, Max: 1}, nil
+	}
+
+	return e, nil
+}
+
+func acceptSymbol(r parser.Reader[*gToken], s string) bool {
+	_, ok := aldana.Accept(r, isSymbol(s), "'"+s+"'")
+	return ok
+}
+
+func parsePrimary(r parser.Reader[*gToken], f aldana.ParseRuleFinder[*gToken, any]) (any, error) {
+	if t, ok := aldana.Accept(r, isIdent, "identifier"); ok {
+		if isKindName(t.Text()) {
+			return &Kind{Name: t.Text()}, nil
+		}
+		return &Ref{Name: t.Text()}, nil
+	}
+
+	if t, ok := aldana.Accept(r, token.IsKind(literalKind), "literal"); ok {
+		return &Literal{Value: t.Value.(string)}, nil
+	}
+
+	groups := []struct {
+		open, close string
+		min, max    int
+		repeat      bool
+	}{
+		{"(", ")", 0, 0, false},
+		{"[", "]", 0, 1, true},
+		{"{", "}", 0, -1, true},
+	}
+
+	for _, g := range groups {
+		if !acceptSymbol(r, g.open) {
+			continue
+		}
+
+		nd, err := f("choice", r)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := aldana.Expect(r, isSymbol(g.close), "'"+g.close+"'"); err != nil {
+			return nil, err
+		}
+
+		if !g.repeat {
+			return nd, nil
+		}
+
+		return &Repeat{Expr: nd.(Expr), Min: g.min, Max: g.max}, nil
+	}
+
+	_, err := aldana.Expect(r, startsPrimary, "expression")
+	return nil, err
+}
+
+var grammarParser = aldana.NewParser(&aldana.ParserOptions[*gToken, any]{
+	ParseRules: map[string]aldana.NodeRule[*gToken, any]{
+		"grammar":    parseGrammar,
+		"production": parseProduction,
+		"choice":     parseChoice,
+		"sequence":   parseSequence,
+		"postfix":    parsePostfix,
+		"primary":    parsePrimary,
+	},
+	Root: "grammar",
+})
+
+// Parse returns the Grammar described by the text src.
+//
+// # About the notation
+//   - A production is declared as: name = expr ;
+//   - The expressions are: a b (sequence), a | b (ordered choice), (a) (group), [a] or a? (optional), {a} or a* (zero or more)
+//     and a+ (one or more).
+//   - The terminals are literals, quoted by " or ', which match the tokens by value; and names without lowercase letters,
+//     such as IDENT, which match the tokens by kind. Any other name refers to a production.
+//   - The comments start with # until the end of the line.
+//
+// # Example
+//
+//	g, err := Parse(`
+//		program = { decl } ;
+//		decl    = "let" IDENT "=" value ";" ;
+//		value   = NUMBER | STRING | IDENT ;
+//	`)
+func Parse(src string) (*Grammar, error) {
+	tks, err := grammarLexer.Tokenize(aldana.NewCursor([]byte(src)))
+	if err != nil {
+		return nil, fmt.Errorf("grammar: %w", err)
+	}
+
+	if len(tks) == 0 {
+		return nil, ErrEmptyGrammar
+	}
+
+	nd, err := grammarParser.Parse(aldana.NewReader(tks))
+
+	if err != nil {
+		return nil, fmt.Errorf("grammar: %w", err)
+	}
+
+	return nd.(*Grammar), nil
+}
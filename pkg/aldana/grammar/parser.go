@@ -0,0 +1,204 @@
+package grammar
+
+import (
+	"fmt"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// Action is a function that returns the node of a production made by the nodes of its matched terminals and non-terminals,
+// in order. The optional expressions that did not match have no nodes.
+type Action[Tn any] func(nds []Tn) Tn
+
+// ParserOptions contains the options for build a parser from a Grammar.
+type ParserOptions[Tt any, Tn any] struct {
+	// Literal returns the predicate of the tokens whose value is v.
+	Literal func(v string) aldana.TokenPredicate[Tt]
+	// Kind returns the predicate of the tokens whose kind is k.
+	Kind func(k string) aldana.TokenPredicate[Tt]
+	// Leaf returns the node of a matched terminal token.
+	Leaf func(t Tt) Tn
+	// Actions are the actions of the productions, identified by the production's name.
+	Actions map[string]Action[Tn]
+	// Node returns the node of the productions without Action, named n. When is nil, such productions result in the first node.
+	Node func(n string, nds []Tn) Tn
+	// Rules are hand-written rules that take the place of the productions with the same name, or add new ones.
+	Rules map[string]aldana.NodeRule[Tt, Tn]
+	// Start is the name of the root production. When is empty, the first production is the root.
+	Start string
+	// Memoize makes the parser remember the result of every production at every position, which allows the left
+	// recursive productions. See aldana.ParserOptions.
+	Memoize bool
+}
+
+// Action returns the action of the production named n: its Action, or the Node function, or the first node.
+func (ops *ParserOptions[Tt, Tn]) Action(n string) Action[Tn] {
+	if act, ok := ops.Actions[n]; ok {
+		return act
+	}
+
+	return func(nds []Tn) Tn {
+		if ops.Node != nil {
+			return ops.Node(n, nds)
+		}
+		if len(nds) == 0 {
+			return *new(Tn)
+		}
+		return nds[0]
+	}
+}
+
+// fragment is the result of an expression: the nodes of its matched terminals and non-terminals.
+type fragment[Tn any] []Tn
+
+func concat[Tn any](fs []fragment[Tn]) fragment[Tn] {
+	var nds fragment[Tn]
+	for _, f := range fs {
+		nds = append(nds, f...)
+	}
+	return nds
+}
+
+// compile returns the combinator of the expression e, or ErrUnknownExpression when e is not an expression of the package.
+func compile[Tt any, Tn any](e Expr, ops *ParserOptions[Tt, Tn]) (aldana.NodeRule[Tt, fragment[Tn]], error) {
+	leaf := func(t Tt) fragment[Tn] {
+		return fragment[Tn]{ops.Leaf(t)}
+	}
+
+	switch e := e.(type) {
+	case *Literal:
+		return aldana.Token(ops.Literal(e.Value), "'"+e.Value+"'", leaf), nil
+	case *Kind:
+		return aldana.Token(ops.Kind(e.Name), e.Name, leaf), nil
+	case *Ref:
+		return aldana.Rule[Tt, fragment[Tn]](e.Name), nil
+	case *Sequence:
+		rs, err := compileAll(e.Items, ops)
+		if err != nil {
+			return nil, err
+		}
+		return aldana.Seq(concat[Tn], rs...), nil
+	case *Choice:
+		rs, err := compileAll(e.Alts, ops)
+		if err != nil {
+			return nil, err
+		}
+		return aldana.Alt(rs...), nil
+	case *Repeat:
+		r, err := compile(e.Expr, ops)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case e.Max == 1:
+			return aldana.Optional(r), nil
+		case e.Min == 0:
+			return aldana.Many(r, concat[Tn]), nil
+		default:
+			return aldana.Many1(r, concat[Tn]), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrUnknownExpression, e)
+}
+
+// compileAll returns the combinators of the expressions es, in order.
+func compileAll[Tt any, Tn any](es []Expr, ops *ParserOptions[Tt, Tn]) ([]aldana.NodeRule[Tt, fragment[Tn]], error) {
+	rs := make([]aldana.NodeRule[Tt, fragment[Tn]], len(es))
+
+	for i, e := range es {
+		r, err := compile(e, ops)
+		if err != nil {
+			return nil, err
+		}
+		rs[i] = r
+	}
+
+	return rs, nil
+}
+
+// Compile returns the rules of the productions of the grammar g, which can be used as aldana.ParserOptions.ParseRules.
+// The Rules of the options take the place of the productions with the same name. Returns ErrUnknownExpression when a
+// production has an expression that is not of the package.
+func Compile[Tt any, Tn any](g *Grammar, ops *ParserOptions[Tt, Tn]) (map[string]aldana.NodeRule[Tt, Tn], error) {
+	rules := make(map[string]aldana.NodeRule[Tt, Tn], len(g.Productions)+len(ops.Rules))
+
+	for _, p := range g.Productions {
+		n := p.Name
+		e, err := compile(p.Expr, ops)
+		if err != nil {
+			return nil, fmt.Errorf("grammar: %s: %w", n, err)
+		}
+
+		act := ops.Action(n)
+
+		rules[n] = func(r parser.Reader[Tt], f aldana.ParseRuleFinder[Tt, Tn]) (Tn, error) {
+			nds, err := e(r, func(n string, r parser.Reader[Tt]) (fragment[Tn], error) {
+				nd, err := f(n, r)
+				if err != nil {
+					return nil, err
+				}
+				return fragment[Tn]{nd}, nil
+			})
+
+			if err != nil {
+				return *new(Tn), err
+			}
+
+			return act(nds), nil
+		}
+	}
+
+	for n, r := range ops.Rules {
+		rules[n] = r
+	}
+
+	return rules, nil
+}
+
+// NewParser returns a parser.Parser for the grammar g.
+//
+// # About the implementation
+//   - The productions are compiled to the combinators of aldana, so the choices are ordered and backtrack.
+//   - The literals are labeled as 'value' and the kinds by their name in the syntax errors.
+//   - The left recursive productions need the Memoize option, otherwise they never end.
+//   - The error of Compile is returned, when a production cannot be compiled.
+//   - The tokens that remain after the start production fail with parser.ErrUnhandledToken, at the first of them.
+//
+// # Example
+//
+//	g, _ := Parse(`decl = "let" IDENT "=" value ";" ; value = NUMBER | IDENT ;`)
+//
+//	prs, err := NewParser(g, &ParserOptions[*token.Token[string], *Node]{
+//		Literal: func(v string) aldana.TokenPredicate[*token.Token[string]] {
+//			return token.IsRaw[string](v)
+//		},
+//		Kind: func(k string) aldana.TokenPredicate[*token.Token[string]] {
+//			return token.IsKind(k)
+//		},
+//		Leaf: NewLeafNode,
+//		Actions: map[string]Action[*Node]{
+//			"decl": func(nds []*Node) *Node {
+//				return NewDeclNode(nds[1], nds[3])
+//			},
+//		},
+//	})
+func NewParser[Tt any, Tn any](g *Grammar, ops *ParserOptions[Tt, Tn]) (parser.Parser[Tt, Tn], error) {
+	s := ops.Start
+
+	if s == "" {
+		s = g.Start()
+	}
+
+	rules, err := Compile(g, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return aldana.NewParser(&aldana.ParserOptions[Tt, Tn]{
+		ParseRules: rules,
+		Root:       s,
+		Memoize:    ops.Memoize,
+	}), nil
+}
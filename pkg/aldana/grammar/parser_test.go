@@ -0,0 +1,204 @@
+package grammar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func isLiteral(v string) aldana.TokenPredicate[string] {
+	return func(t string) bool {
+		return t == v
+	}
+}
+
+func isKind(k string) aldana.TokenPredicate[string] {
+	return func(t string) bool {
+		switch k {
+		case "NUMBER":
+			return strings.Trim(t, "0123456789") == ""
+		case "IDENT":
+			return strings.Trim(t, "abcdefghijklmnopqrstuvwxyz") == ""
+		}
+		return false
+	}
+}
+
+func setUpGrammar(t *testing.T) *Grammar {
+	g, err := Parse(`
+		program = decl+ ;
+		decl    = "let" IDENT "=" value ";" ;
+		value   = NUMBER | IDENT | "[" [ value { "," value } ] "]" ;
+	`)
+	assert.NoError(t, err)
+	return g
+}
+
+/*
+Given: a parser for a grammar, that builds a Tree.
+When: parses acceptable tokens.
+Then: returns the Tree of the productions.
+*/
+func TestNewParser_with_tree(t *testing.T) {
+	// arrange
+	prs, err := NewParser(setUpGrammar(t), TreeOptions(isLiteral, isKind))
+	assert.NoError(t, err)
+
+	// act
+	tr, err := prs.Parse(aldana.NewReader([]string{"let", "a", "=", "1", ";", "let", "b", "=", "[", "a", ",", "2", "]", ";"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "(program (decl let a = (value 1) ;) (decl let b = (value [ (value a) , (value 2) ]) ;))", tr.String())
+}
+
+/*
+Given: a parser for a grammar, with actions and a hand-written rule.
+When: parses acceptable tokens.
+Then: returns the nodes built by the actions and the rule.
+*/
+func TestNewParser_with_actions(t *testing.T) {
+	// arrange
+	prs, err := NewParser(setUpGrammar(t), &ParserOptions[string, string]{
+		Literal: isLiteral,
+		Kind:    isKind,
+		Leaf: func(t string) string {
+			return t
+		},
+		Actions: map[string]Action[string]{
+			"program": func(nds []string) string {
+				return strings.Join(nds, " ")
+			},
+			"decl": func(nds []string) string {
+				return nds[1] + ":" + nds[3]
+			},
+		},
+		Rules: map[string]aldana.NodeRule[string, string]{
+			"value": func(r parser.Reader[string], f aldana.ParseRuleFinder[string, string]) (string, error) {
+				t, err := aldana.Expect(r, isKind("NUMBER"), "NUMBER")
+				return "#" + t, err
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// act
+	nd, err := prs.Parse(aldana.NewReader([]string{"let", "a", "=", "1", ";", "let", "b", "=", "2", ";"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "a:#1 b:#2", nd)
+}
+
+/*
+Given: a parser for a grammar.
+When: parses non acceptable tokens.
+Then: returns a parser.SyntaxError with the expected terminals.
+*/
+func TestNewParser_with_non_acceptable_tokens(t *testing.T) {
+	// arrange
+	prs, err := NewParser(setUpGrammar(t), TreeOptions(isLiteral, isKind))
+	assert.NoError(t, err)
+
+	// act
+	_, err = prs.Parse(aldana.NewReader([]string{"let", "a", "=", "[", "1", "2", "]", ";"}))
+
+	// assert
+	var sErr *parser.SyntaxError
+	assert.ErrorAs(t, err, &sErr)
+	assert.Equal(t, "expected ',' or ']' but found 2", sErr.Error())
+}
+
+/*
+Given: a memoized parser for a left recursive grammar.
+When: parses acceptable tokens.
+Then: returns the Tree grouped by the left recursion.
+*/
+func TestNewParser_with_left_recursion(t *testing.T) {
+	// arrange
+	g, err := Parse(`expr = expr "-" term | term ; term = term "*" NUMBER | NUMBER ;`)
+	assert.NoError(t, err)
+	ops := TreeOptions(isLiteral, isKind)
+	ops.Memoize = true
+	prs, err := NewParser(g, ops)
+	assert.NoError(t, err)
+
+	// act
+	tr, err := prs.Parse(aldana.NewReader([]string{"1", "-", "2", "*", "3", "-", "4"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "(expr (expr (expr (term 1)) - (term (term 2) * 3)) - (term 4))", tr.String())
+}
+
+// unknownExpr is an expression that is not of the package.
+type unknownExpr struct{}
+
+func (unknownExpr) String() string {
+	return "?"
+}
+
+/*
+Given: a grammar with an expression that is not of the package.
+When: makes its parser.
+Then: returns ErrUnknownExpression with the production, instead of panicking.
+*/
+func TestNewParser_with_unknown_expression(t *testing.T) {
+	// arrange
+	g := &Grammar{Productions: []*Production{
+		{Name: "program", Expr: &Sequence{Items: []Expr{&Ref{Name: "value"}, unknownExpr{}}}},
+	}}
+
+	// act
+	prs, err := NewParser(g, TreeOptions(isLiteral, isKind))
+
+	// assert
+	assert.Nil(t, prs)
+	assert.ErrorIs(t, err, ErrUnknownExpression)
+	assert.EqualError(t, err, "grammar: program: the expression is not known: ?")
+}
+
+/*
+Given: parsers for grammars of a single terminal and of a declaration.
+When: parses a single token, and a declaration with a trailing token.
+Then: returns the Tree of the single token, and fails with parser.ErrUnhandledToken at the trailing token.
+*/
+func TestNewParser_remaining_tokens(t *testing.T) {
+	cases := map[string]struct {
+		grammar string
+		tokens  []string
+		expect  string
+		err     error
+	}{
+		"single token":   {grammar: `v = IDENT ;`, tokens: []string{"x"}, expect: "(v x)"},
+		"trailing token": {grammar: `decl = "let" IDENT ;`, tokens: []string{"let", "x", "y"}, err: parser.ErrUnhandledToken},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			g, err := Parse(c.grammar)
+			assert.NoError(t, err)
+			prs, err := NewParser(g, TreeOptions(isLiteral, isKind))
+			assert.NoError(t, err)
+
+			// act
+			tr, err := prs.Parse(aldana.NewReader(c.tokens))
+
+			// assert
+			if c.err != nil {
+				var pErr *aldana.ParseError
+				assert.ErrorIs(t, err, c.err)
+				assert.ErrorAs(t, err, &pErr)
+				assert.Equal(t, "y", pErr.Token)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, tr.String())
+		})
+	}
+}
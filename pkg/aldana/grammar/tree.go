@@ -0,0 +1,67 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+)
+
+// Tree is a generic parse tree. Where the leaves are the matched tokens, and the branches are the matched productions.
+type Tree[Tt any] struct {
+	// Rule is the name of the production. Empty for the leaves.
+	Rule string
+	// Token is the matched token of the leaves.
+	Token Tt
+	// Children are the trees of the production.
+	Children []*Tree[Tt]
+}
+
+// IsLeaf returns a boolean that indicates whether the tree is a matched token.
+func (t *Tree[Tt]) IsLeaf() bool {
+	return t.Rule == ""
+}
+
+// String returns the tree as a s-expression: (rule child...), where the leaves are their tokens.
+func (t *Tree[Tt]) String() string {
+	if t.IsLeaf() {
+		return fmt.Sprintf("%v", t.Token)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("(")
+	b.WriteString(t.Rule)
+
+	for _, c := range t.Children {
+		b.WriteString(" ")
+		b.WriteString(c.String())
+	}
+
+	b.WriteString(")")
+
+	return b.String()
+}
+
+// TreeOptions returns the ParserOptions that build a Tree for every production, with the predicates of the terminals
+// literal and kind.
+//
+// # Example
+//
+//	prs, err := NewParser(g, TreeOptions(func(v string) aldana.TokenPredicate[*token.Token[string]] {
+//		return token.IsRaw[string](v)
+//	}, func(k string) aldana.TokenPredicate[*token.Token[string]] {
+//		return token.IsKind(k)
+//	}))
+func TreeOptions[Tt any](literal func(v string) aldana.TokenPredicate[Tt], kind func(k string) aldana.TokenPredicate[Tt]) *ParserOptions[Tt, *Tree[Tt]] {
+	return &ParserOptions[Tt, *Tree[Tt]]{
+		Literal: literal,
+		Kind:    kind,
+		Leaf: func(t Tt) *Tree[Tt] {
+			return &Tree[Tt]{Token: t}
+		},
+		Node: func(n string, nds []*Tree[Tt]) *Tree[Tt] {
+			return &Tree[Tt]{Rule: n, Children: nds}
+		},
+	}
+}
@@ -0,0 +1,200 @@
+package aldana
+
+import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+
+// TokenEdit is an edit of the tokens, where the ones from Start to OldEnd were replaced by the ones from Start to NewEnd.
+type TokenEdit struct {
+	Start  int
+	OldEnd int
+	NewEnd int
+}
+
+// DiffTokens returns the TokenEdit that makes the old tokens the new ones, by their common prefix and suffix.
+// Where eq indicates whether two tokens are the same.
+func DiffTokens[Tt any](old, new []Tt, eq func(a, b Tt) bool) TokenEdit {
+	n := len(old)
+	if len(new) < n {
+		n = len(new)
+	}
+
+	s := 0
+	for s < n && eq(old[s], new[s]) {
+		s++
+	}
+
+	e := 0
+	for e < n-s && eq(old[len(old)-1-e], new[len(new)-1-e]) {
+		e++
+	}
+
+	return TokenEdit{Start: s, OldEnd: len(old) - e, NewEnd: len(new) - e}
+}
+
+// lookReader is a parser.Reader that records the first and the last positions of the tokens that were read.
+// The expected labels are recorded by the wrapped reader when it records them, otherwise by its own expectations.
+type lookReader[Tt any] struct {
+	parser.Reader[Tt]
+	own    expectations
+	lo, hi int
+}
+
+func (r *lookReader[Tt]) recorder() expectationRecorder {
+	if er, ok := r.Reader.(expectationRecorder); ok {
+		return er
+	}
+	return &r.own
+}
+
+func (r *lookReader[Tt]) expect(p int, l string) {
+	r.recorder().expect(p, l)
+}
+
+func (r *lookReader[Tt]) expected() (int, []string) {
+	return r.recorder().expected()
+}
+
+func (r *lookReader[Tt]) restore(p int, l []string) {
+	r.recorder().restore(p, l)
+}
+
+func (r *lookReader[Tt]) touch(i int) {
+	if i < r.lo {
+		r.lo = i
+	}
+	if i > r.hi {
+		r.hi = i
+	}
+}
+
+func (r *lookReader[Tt]) HasTokens() bool {
+	r.touch(r.Position() + 1)
+	return r.Reader.HasTokens()
+}
+
+func (r *lookReader[Tt]) GetToken() Tt {
+	r.touch(r.Position())
+	return r.Reader.GetToken()
+}
+
+func (r *lookReader[Tt]) Next() {
+	r.touch(r.Position() + 1)
+	r.Reader.Next()
+}
+
+func (r *lookReader[Tt]) Peek(n int) (Tt, bool) {
+	r.touch(r.Position() + n)
+	return r.Reader.Peek(n)
+}
+
+// ParseTree is the node of a parse, with the results of the rules by their token spans, so it can be reparsed after an
+// edit of its tokens.
+type ParseTree[Tt any, Tn any] struct {
+	// Node is the node of the Root.
+	Node Tn
+	// Tokens are the parsed tokens.
+	Tokens []Tt
+	// results are the results of the rules that only depend on the tokens they read.
+	results map[memoKey]*memoEntry[Tn]
+}
+
+// IncrementalParser is a parser.RuleParser that reparses the tokens after an edit, reusing the results of the rules that
+// did not read the edited tokens.
+type IncrementalParser[Tt any, Tn any] interface {
+	parser.RuleParser[Tt, Tn]
+	// ParseTree parses the tokens from the Root, and returns the ParseTree even when the parse fails.
+	ParseTree(tks []Tt) (*ParseTree[Tt, Tn], error)
+	// Reparse parses the tokens, which are the tokens of the old tree after the edit e, and returns the new ParseTree.
+	Reparse(old *ParseTree[Tt, Tn], e TokenEdit, tks []Tt) (*ParseTree[Tt, Tn], error)
+}
+
+// incrementalParser implements IncrementalParser by seeding the memo of the parse with the results of the old tree.
+type incrementalParser[Tt any, Tn any] struct {
+	*defaultParser[Tt, Tn]
+}
+
+func (p *incrementalParser[Tt, Tn]) ParseTree(tks []Tt) (*ParseTree[Tt, Tn], error) {
+	return p.parseTree(map[memoKey]*memoEntry[Tn]{}, tks)
+}
+
+func (p *incrementalParser[Tt, Tn]) Reparse(old *ParseTree[Tt, Tn], e TokenEdit, tks []Tt) (*ParseTree[Tt, Tn], error) {
+	if old == nil {
+		return p.ParseTree(tks)
+	}
+
+	d := e.NewEnd - e.OldEnd
+	memo := make(map[memoKey]*memoEntry[Tn], len(old.results))
+
+	for k, en := range old.results {
+		switch {
+		case en.hi < e.Start:
+			memo[k] = en
+		case en.lo >= e.OldEnd:
+			s := *en
+			s.end, s.lo, s.hi = s.end+d, s.lo+d, s.hi+d
+			memo[memoKey{rule: k.rule, pos: k.pos + d}] = &s
+		}
+	}
+
+	return p.parseTree(memo, tks)
+}
+
+// parseTree parses the tokens from the Root, where the memo has the reused results.
+func (p *incrementalParser[Tt, Tn]) parseTree(memo map[memoKey]*memoEntry[Tn], tks []Tt) (*ParseTree[Tt, Tn], error) {
+	if p.invalid != nil {
+		return nil, p.invalid
+	}
+
+	rules := p.ops.rules()
+
+	if _, ok := rules[p.ops.Root]; !ok {
+		return nil, ErrNotFoundRootParserRule
+	}
+
+	r := &lookReader[Tt]{Reader: NewReader(tks)}
+	r.Next()
+
+	if !atToken[Tt](r) {
+		return nil, ErrNoTokenToParser
+	}
+
+	run := &parseRun[Tt, Tn]{ops: p.ops, rules: rules, memo: memo, look: r}
+
+	nd, err := p.parseWith(run, p.ops.Root, r, func() bool {
+		return atToken[Tt](r)
+	})
+
+	t := &ParseTree[Tt, Tn]{Node: nd, Tokens: tks, results: make(map[memoKey]*memoEntry[Tn], len(run.memo))}
+
+	for k, en := range run.memo {
+		if !en.parsing && !en.seeded {
+			t.results[k] = en
+		}
+	}
+
+	return t, err
+}
+
+// NewIncrementalParser returns an IncrementalParser with the options ops, which parses as with the Memoize option.
+//
+// # About the implementation
+//   - Every result of a rule records the first and the last positions of the tokens that it read, including the lookahead.
+//   - After an edit, the results that read only tokens before it are reused, and the ones that read only tokens after it
+//     are reused at their shifted positions. The other rules are parsed again, so only the edited region is parsed.
+//   - The reused nodes are the same values of the old tree, so they keep the tokens of the old one.
+//   - The results that depend on the seed of a left recursion are not reused, but the left recursive rule is.
+//   - The rules must only depend on their tokens: a Context or a Recovery is not replayed for the reused results.
+//
+// # Example
+//
+//	prs := NewIncrementalParser(&ParserOptions[*Token, *Node]{ParseRules: rules, Root: "program"})
+//
+//	tree, err := prs.ParseTree(tks)
+//
+//	// after the user edits the source and it is tokenized again
+//	tree, err = prs.Reparse(tree, DiffTokens(tree.Tokens, newTks, SameToken), newTks)
+func NewIncrementalParser[Tt any, Tn any](ops *ParserOptions[Tt, Tn]) IncrementalParser[Tt, Tn] {
+	o := *ops
+	o.Memoize = true
+
+	return &incrementalParser[Tt, Tn]{NewParser(&o).(*defaultParser[Tt, Tn])}
+}
@@ -0,0 +1,219 @@
+package aldana
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func sameToken(a, b string) bool {
+	return a == b
+}
+
+// setUpIncrementalParser returns a parser of assignments, and the count of the parsed statements.
+func setUpIncrementalParser() (IncrementalParser[string, *node], *int) {
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+
+	count := new(int)
+	assign := Seq(listNode, Token(isIdentifier, "identifier", leafNode), sym("="), Token(isDigit, "digit", leafNode), sym(";"))
+
+	return NewIncrementalParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program": Many1(Rule[string, *node]("statement"), listNode),
+			"statement": func(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+				*count++
+				return assign(r, f)
+			},
+		},
+		Root: "program",
+	}), count
+}
+
+/*
+Given: the old and the new tokens.
+When: diffs them.
+Then: returns the edit between their common prefix and suffix.
+*/
+func TestDiffTokens(t *testing.T) {
+	cases := map[string]struct {
+		old    string
+		new    string
+		expect TokenEdit
+	}{
+		"replace": {old: "a b c d", new: "a x y d", expect: TokenEdit{Start: 1, OldEnd: 3, NewEnd: 3}},
+		"insert":  {old: "a b", new: "a x b", expect: TokenEdit{Start: 1, OldEnd: 1, NewEnd: 2}},
+		"delete":  {old: "a b b c", new: "a b c", expect: TokenEdit{Start: 2, OldEnd: 3, NewEnd: 2}},
+		"append":  {old: "a", new: "a b", expect: TokenEdit{Start: 1, OldEnd: 1, NewEnd: 2}},
+		"same":    {old: "a b", new: "a b", expect: TokenEdit{Start: 2, OldEnd: 2, NewEnd: 2}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			e := DiffTokens(strings.Fields(c.old), strings.Fields(c.new), sameToken)
+
+			// assert
+			assert.Equal(t, c.expect, e)
+		})
+	}
+}
+
+/*
+Given: an incremental parser and the tree of some statements.
+When: reparses the tokens after an edit.
+Then: parses again only the edited statements, and returns the same node as a whole parse.
+*/
+func TestIncrementalParser_Reparse(t *testing.T) {
+	cases := map[string]struct {
+		new    string
+		parsed int
+		// reused are the indexes of a reused statement in the old and in the new node.
+		reused [2]int
+	}{
+		"replace in the middle": {new: "a = 1 ; b = 5 ; c = 3 ;", parsed: 1, reused: [2]int{2, 2}},
+		"insert at the start":   {new: "d = 4 ; a = 1 ; b = 2 ; c = 3 ;", parsed: 1, reused: [2]int{2, 3}},
+		"delete at the end":     {new: "a = 1 ; b = 2 ;", parsed: 1, reused: [2]int{0, 0}},
+		"append at the end":     {new: "a = 1 ; b = 2 ; c = 3 ; d = 4 ;", parsed: 2, reused: [2]int{1, 1}},
+	}
+
+	old := strings.Fields("a = 1 ; b = 2 ; c = 3 ;")
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs, count := setUpIncrementalParser()
+			tree, err := prs.ParseTree(old)
+			assert.NoError(t, err)
+
+			tks := strings.Fields(c.new)
+			expect, err := prs.ParseRule("program", NewReader(tks))
+			assert.NoError(t, err)
+			*count = 0
+
+			// act
+			nt, err := prs.Reparse(tree, DiffTokens(tree.Tokens, tks, sameToken), tks)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, expect, nt.Node)
+			assert.Equal(t, c.parsed, *count)
+			assert.Same(t, tree.Node.Children[c.reused[0]], nt.Node.Children[c.reused[1]])
+		})
+	}
+}
+
+/*
+Given: an incremental parser and the tree of non acceptable tokens.
+When: reparses the tokens after fixing them.
+Then: returns the node of the fixed tokens.
+*/
+func TestIncrementalParser_Reparse_after_an_error(t *testing.T) {
+	// arrange
+	prs, _ := setUpIncrementalParser()
+	old := strings.Fields("a = 1 ; b = ; c = 3 ;")
+	tks := strings.Fields("a = 1 ; b = 2 ; c = 3 ;")
+
+	tree, oErr := prs.ParseTree(old)
+
+	// act
+	nt, err := prs.Reparse(tree, DiffTokens(old, tks, sameToken), tks)
+
+	// assert
+	assert.ErrorIs(t, oErr, parser.ErrUnhandledToken)
+	assert.NoError(t, err)
+	assert.Len(t, nt.Node.Children, 3)
+}
+
+/*
+Given: an incremental parser and a parser with the same options, and tokens where an alternative fails.
+When: parses the tokens by both.
+Then: returns the same error, with the labels of every alternative.
+*/
+func TestIncrementalParser_ParseTree_with_expected_labels(t *testing.T) {
+	// arrange
+	ops := &ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program": Many1(Rule[string, *node]("value"), listNode),
+			"value":   Alt(Token(isToken("a"), "'a'", leafNode), Token(isToken("b"), "'b'", leafNode)),
+		},
+		Root: "program",
+	}
+	tks := strings.Fields("c")
+
+	// act
+	_, err := NewIncrementalParser(ops).ParseTree(tks)
+	_, fErr := NewParser(ops).Parse(NewReader(tks))
+
+	// assert
+	var se *parser.SyntaxError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, []string{"'a'", "'b'"}, se.Expected)
+	assert.EqualError(t, err, fErr.Error())
+}
+
+/*
+Given: an incremental parser with left recursive rules.
+When: reparses the tokens after an edit.
+Then: returns the same node as a whole parse.
+*/
+func TestIncrementalParser_Reparse_with_left_recursion(t *testing.T) {
+	num := Token(isDigit, "digit", leafNode)
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+
+	cases := map[string]struct {
+		rules map[string]NodeRule[string, *node]
+		old   string
+		new   string
+	}{
+		"direct append": {
+			rules: map[string]NodeRule[string, *node]{
+				"expr": Alt(Seq(binaryNode, Rule[string, *node]("expr"), sym("-"), Rule[string, *node]("term")), Rule[string, *node]("term")),
+				"term": Alt(Seq(binaryNode, Rule[string, *node]("term"), sym("*"), num), num),
+			},
+			old: "1 - 2 * 3",
+			new: "1 - 2 * 3 * 4 - 5",
+		},
+		"indirect replace": {
+			rules: map[string]NodeRule[string, *node]{
+				"expr": Alt(Rule[string, *node]("sub"), num),
+				"sub":  Seq(binaryNode, Rule[string, *node]("expr"), sym("-"), num),
+			},
+			old: "1 - 2 - 3 - 4",
+			new: "1 - 2 - 9 - 4",
+		},
+		"indirect insert": {
+			rules: map[string]NodeRule[string, *node]{
+				"expr": Alt(Rule[string, *node]("sub"), num),
+				"sub":  Seq(binaryNode, Rule[string, *node]("expr"), sym("-"), num),
+			},
+			old: "1 - 2",
+			new: "0 - 1 - 2",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs := NewIncrementalParser(&ParserOptions[string, *node]{ParseRules: c.rules, Root: "expr"})
+			old, tks := strings.Fields(c.old), strings.Fields(c.new)
+			tree, err := prs.ParseTree(old)
+			assert.NoError(t, err)
+
+			expect, err := prs.ParseRule("expr", NewReader(tks))
+			assert.NoError(t, err)
+
+			// act
+			nt, err := prs.Reparse(tree, DiffTokens(old, tks, sameToken), tks)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, nodeString(expect), nodeString(nt.Node))
+		})
+	}
+}
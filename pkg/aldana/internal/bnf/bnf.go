@@ -0,0 +1,291 @@
+// Package bnf contains the grammars of the package grammar without EBNF expressions, used by the table and chart parsers.
+package bnf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+)
+
+// End is the terminal of the end of the tokens.
+const End = 0
+
+// Accept is the production of the augmented start: $accept = start.
+const Accept = 0
+
+// Symbol is a terminal or a non-terminal of the BNF.
+type Symbol struct {
+	// Name is the name in the grammar notation: "let" for a literal, IDENT for a kind, or the name of the production.
+	// The helpers are named by the EBNF expression that they replace.
+	Name     string
+	Terminal bool
+	// Literal indicates whether the terminal is matched by value, otherwise it is matched by kind.
+	Literal bool
+	// Value is the value of the literals or the kind of the kinds.
+	Value string
+	// Helper indicates whether the non-terminal replaces an EBNF expression, so its nodes belong to its parent.
+	Helper bool
+	// Prods are the indexes of the productions of the non-terminal.
+	Prods []int
+}
+
+// Label returns the label of the terminal in the syntax errors.
+func (s *Symbol) Label() string {
+	switch {
+	case s.Name == "$":
+		return "end of the tokens"
+	case s.Literal:
+		return "'" + s.Value + "'"
+	default:
+		return s.Value
+	}
+}
+
+// Production is a production of the BNF: LHS = RHS.
+type Production struct {
+	LHS int
+	RHS []int
+	// Alt is the index of the alternative of the grammar's production, when its expression is a choice.
+	Alt int
+}
+
+// BNF is a grammar without EBNF expressions, where the first production is the augmented start: $accept = start.
+type BNF struct {
+	Syms  []Symbol
+	Prods []Production
+	// Nullable and First are indexed by symbol.
+	Nullable []bool
+	First    []map[int]bool
+	byName   map[string]int
+}
+
+// symbolOf returns the index of the symbol named n, adding it by f when it does not exist.
+func (b *BNF) symbolOf(n string, f func() Symbol) (int, bool) {
+	if i, ok := b.byName[n]; ok {
+		return i, false
+	}
+
+	b.Syms = append(b.Syms, f())
+	b.byName[n] = len(b.Syms) - 1
+
+	return len(b.Syms) - 1, true
+}
+
+func (b *BNF) addProduction(lhs int, rhs []int, alt int) {
+	b.Prods = append(b.Prods, Production{LHS: lhs, RHS: rhs, Alt: alt})
+	b.Syms[lhs].Prods = append(b.Syms[lhs].Prods, len(b.Prods)-1)
+}
+
+func (b *BNF) nonTerminal(n string) int {
+	i, _ := b.symbolOf(n, func() Symbol {
+		return Symbol{Name: n}
+	})
+	return i
+}
+
+// helper returns the helper non-terminal of the expression e, adding its productions when it does not exist.
+// Equal expressions share the same helper.
+func (b *BNF) helper(e grammar.Expr) int {
+	h, isNew := b.symbolOf(e.String(), func() Symbol {
+		return Symbol{Name: e.String(), Helper: true}
+	})
+
+	if !isNew {
+		return h
+	}
+
+	switch e := e.(type) {
+	case *grammar.Choice:
+		for i, a := range e.Alts {
+			b.addProduction(h, b.desugar(a), i)
+		}
+	case *grammar.Repeat:
+		x := b.desugar(e.Expr)
+		switch {
+		case e.Max == 1:
+			b.addProduction(h, nil, 0)
+			b.addProduction(h, x, 1)
+		case e.Min == 0:
+			b.addProduction(h, nil, 0)
+			b.addProduction(h, append([]int{h}, x...), 1)
+		default:
+			b.addProduction(h, x, 0)
+			b.addProduction(h, append([]int{h}, x...), 1)
+		}
+	}
+
+	return h
+}
+
+// desugar returns the symbols of the expression e, where the choices and repeats are replaced by helpers.
+func (b *BNF) desugar(e grammar.Expr) []int {
+	switch e := e.(type) {
+	case *grammar.Literal:
+		n := strconv.Quote(e.Value)
+		i, _ := b.symbolOf(n, func() Symbol {
+			return Symbol{Name: n, Terminal: true, Literal: true, Value: e.Value}
+		})
+		return []int{i}
+	case *grammar.Kind:
+		i, _ := b.symbolOf(e.Name, func() Symbol {
+			return Symbol{Name: e.Name, Terminal: true, Value: e.Name}
+		})
+		return []int{i}
+	case *grammar.Ref:
+		return []int{b.nonTerminal(e.Name)}
+	case *grammar.Sequence:
+		var s []int
+		for _, it := range e.Items {
+			s = append(s, b.desugar(it)...)
+		}
+		return s
+	}
+
+	return []int{b.helper(e)}
+}
+
+// New returns the BNF of the grammar g, with s as start production.
+func New(g *grammar.Grammar, s string) *BNF {
+	b := &BNF{
+		Syms:   []Symbol{{Name: "$", Terminal: true}},
+		byName: map[string]int{"$": End},
+	}
+
+	acc := b.nonTerminal("$accept")
+	b.Syms[acc].Helper = true
+	b.addProduction(acc, []int{b.nonTerminal(s)}, 0)
+
+	for _, p := range g.Productions {
+		lhs := b.nonTerminal(p.Name)
+
+		if c, ok := p.Expr.(*grammar.Choice); ok {
+			for i, a := range c.Alts {
+				b.addProduction(lhs, b.desugar(a), i)
+			}
+			continue
+		}
+
+		b.addProduction(lhs, b.desugar(p.Expr), 0)
+	}
+
+	b.computeFirst()
+
+	return b
+}
+
+// computeFirst computes the nullable non-terminals and the first terminals of every symbol.
+func (b *BNF) computeFirst() {
+	b.Nullable = make([]bool, len(b.Syms))
+	b.First = make([]map[int]bool, len(b.Syms))
+
+	for i, s := range b.Syms {
+		b.First[i] = map[int]bool{}
+		if s.Terminal {
+			b.First[i][i] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, p := range b.Prods {
+			f, nullable := b.FirstOf(p.RHS)
+
+			if nullable && !b.Nullable[p.LHS] {
+				b.Nullable[p.LHS], changed = true, true
+			}
+
+			for t := range f {
+				if !b.First[p.LHS][t] {
+					b.First[p.LHS][t], changed = true, true
+				}
+			}
+		}
+	}
+}
+
+// FirstOf returns the first terminals of the symbols s, and whether s is nullable.
+func (b *BNF) FirstOf(s []int) (map[int]bool, bool) {
+	f := map[int]bool{}
+
+	for _, x := range s {
+		for t := range b.First[x] {
+			f[t] = true
+		}
+		if !b.Nullable[x] {
+			return f, false
+		}
+	}
+
+	return f, true
+}
+
+// Shortest returns the shortest terminals derived by every non-terminal, or nil when it derives none.
+func (b *BNF) Shortest() [][]string {
+	sh := make([][]string, len(b.Syms))
+	done := make([]bool, len(b.Syms))
+
+	for i, s := range b.Syms {
+		if s.Terminal {
+			sh[i], done[i] = []string{s.Name}, true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, p := range b.Prods {
+			c := []string{}
+			ok := true
+
+			for _, x := range p.RHS {
+				if !done[x] {
+					ok = false
+					break
+				}
+				c = append(c, sh[x]...)
+			}
+
+			if ok && (!done[p.LHS] || len(c) < len(sh[p.LHS])) {
+				sh[p.LHS], done[p.LHS], changed = c, true, true
+			}
+		}
+	}
+
+	return sh
+}
+
+// ItemString returns the production p with a dot before the symbol d: expr = expr . "+" expr
+func (b *BNF) ItemString(p int, d int) string {
+	var s strings.Builder
+
+	s.WriteString(b.Syms[b.Prods[p].LHS].Name)
+	s.WriteString(" =")
+
+	for i, x := range b.Prods[p].RHS {
+		if i == d {
+			s.WriteString(" .")
+		}
+		s.WriteString(" ")
+		s.WriteString(b.Syms[x].Name)
+	}
+
+	if d == len(b.Prods[p].RHS) {
+		s.WriteString(" .")
+	}
+
+	return s.String()
+}
+
+// SortTerminals sorts the terminals x, where the literals are before the kinds.
+func (b *BNF) SortTerminals(x []int) {
+	sort.Slice(x, func(i, j int) bool {
+		a, c := b.Syms[x[i]], b.Syms[x[j]]
+		if a.Literal != c.Literal {
+			return a.Literal
+		}
+		return x[i] < x[j]
+	})
+}
@@ -0,0 +1,29 @@
+// Package grammartest contains the options shared by the tests of the parsers of grammars.
+package grammartest
+
+import (
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+)
+
+// Options returns the options of a grammar.Tree parser of string tokens. Where a literal matches the equal token, NUMBER
+// matches the digits, and IDENT matches the lowercase letters.
+func Options() *grammar.ParserOptions[string, *grammar.Tree[string]] {
+	return grammar.TreeOptions(func(v string) aldana.TokenPredicate[string] {
+		return func(t string) bool {
+			return t == v
+		}
+	}, func(k string) aldana.TokenPredicate[string] {
+		return func(t string) bool {
+			switch k {
+			case "NUMBER":
+				return strings.Trim(t, "0123456789") == ""
+			case "IDENT":
+				return strings.Trim(t, "abcdefghijklmnopqrstuvwxyz") == ""
+			}
+			return false
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package aldana
 
 import (
+	"context"
+	"fmt"
+	"io"
+
 	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
 	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
 )
@@ -8,9 +12,18 @@ import (
 // TokenRule is a function that returns a new token based on some defined rules.
 type TokenRule[T any] func(c lexer.Cursor, r ranges.ByteRange) T
 
+// FallibleTokenRule is a function that returns a new token based on some defined rules, a boolean that indicates
+// whether the token has to be emitted, and an error when the token cannot be made. Use NewFallibleLexicalRule to add it
+// to the LexRules.
+type FallibleTokenRule[T any] func(c lexer.Cursor, r ranges.ByteRange) (T, bool, error)
+
 // LexicalRule is a function that returns a ranges.ByteRange related to a TokenRule.
 type LexicalRule[T any] func() (ranges.ByteRange, TokenRule[T])
 
+// LexicalLookahead is a function that indicates whether a LexicalRule fires at the current char.
+// It only can peek the chars, so the input is not consumed.
+type LexicalLookahead func(p lexer.Peeker) bool
+
 // LexicalOmit is a function that returns a range.ByteRange to omit and the way of how the bytes have to be omitted.
 type LexicalOmit func() (ranges.ByteRange, func(c lexer.Cursor, r ranges.ByteRange))
 
@@ -20,6 +33,10 @@ type LexerOptions[T any] struct {
 	LexRules []LexicalRule[T]
 	// Ignore is a pseudo-lexical-rule that ignores chars.
 	Ignore LexicalOmit
+	// Observer receives the events of the tokenization. Use NewLexerTracer to log them.
+	Observer LexerObserver[T]
+	// Warnings receives the warnings of AnalyzeLexer, one per line, when NewLexer is called.
+	Warnings io.Writer
 }
 
 // defaultLexer implements lexer.Lexer
@@ -27,14 +44,67 @@ type defaultLexer[T any] struct {
 	ops *LexerOptions[T]
 }
 
+// ruleCursor is the lexer.Cursor given to the token rules, where the rules made by NewFallibleLexicalRule report
+// whether their token has to be omitted, and their error.
+type ruleCursor struct {
+	lexer.Cursor
+	omit bool
+	err  error
+}
+
+func (c *ruleCursor) report(em bool, err error) {
+	c.omit, c.err = !em, err
+}
+
+// peekCursor is the ruleCursor of a cursor that implements lexer.Peeker.
+type peekCursor struct {
+	*ruleCursor
+	lexer.Peeker
+}
+
+// reporter is implemented by the cursors given to the token rules by the Lexer.
+type reporter interface {
+	report(em bool, err error)
+}
+
+// newRuleCursor returns the ruleCursor of c, which implements lexer.Peeker when c implements it.
+func newRuleCursor(c lexer.Cursor) (*ruleCursor, lexer.Cursor) {
+	rc := &ruleCursor{Cursor: c}
+
+	if pk, ok := c.(lexer.Peeker); ok {
+		return rc, peekCursor{ruleCursor: rc, Peeker: pk}
+	}
+
+	return rc, rc
+}
+
 func (l *defaultLexer[T]) Tokenize(c lexer.Cursor) ([]T, error) {
 	tks := []T{}
 
+	err := l.Stream(context.Background(), c, func(t T) error {
+		tks = append(tks, t)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tks, nil
+}
+
+func (l *defaultLexer[T]) Stream(ctx context.Context, c lexer.Cursor, emit func(t T) error) error {
 	var (
 		hasIg ranges.ByteRange
 		ig    func(c lexer.Cursor, r ranges.ByteRange)
 	)
 
+	var obs LexerObserver[T] = nopLexerObserver[T]{}
+
+	if l.ops.Observer != nil {
+		obs = l.ops.Observer
+	}
+
 	if l.ops.Ignore == nil {
 		hasIg = func(b byte) bool {
 			return false
@@ -43,30 +113,63 @@ func (l *defaultLexer[T]) Tokenize(c lexer.Cursor) ([]T, error) {
 		hasIg, ig = l.ops.Ignore()
 	}
 
+	rc, rcc := newRuleCursor(c)
+
 	c.Next()
 
 	for c.HasChar() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		p := lexer.PositionOf(c)
+
 		if hasIg(c.GetChar()) {
 			ig(c, hasIg)
+			obs.BytesOmitted(p, lexer.PositionOf(c))
 			continue
 		}
-		var ux bool
+		var ok bool
+
+		for i, lr := range l.ops.LexRules {
+			r, t := lr()
+			ok = r(c.GetChar())
+			obs.RuleTried(i, c.GetChar(), p, ok)
+
+			if !ok {
+				continue
+			}
+
+			rc.omit, rc.err = false, nil
+			tk := t(rcc, r)
+			em, err := !rc.omit, rc.err
+
+			if err != nil {
+				obs.Error(err, p)
+				return &lexer.Error{Err: err, Position: p}
+			}
 
-		for _, lr := range l.ops.LexRules {
-			if r, t := lr(); r(c.GetChar()) {
-				tks = append(tks, t(c, r))
-				ux = false
-				break
+			if e := lexer.PositionOf(c); em {
+				obs.TokenEmitted(i, tk, p, e)
+				if err := emit(tk); err != nil {
+					return err
+				}
+			} else if e != p {
+				obs.BytesOmitted(p, e)
+			} else {
+				ok = false
+				continue
 			}
-			ux = true
+			break
 		}
 
-		if ux {
-			return nil, lexer.ErrUnexpectedChar
+		if !ok {
+			obs.Error(lexer.ErrUnexpectedChar, p)
+			return &lexer.Error{Err: lexer.ErrUnexpectedChar, Position: p}
 		}
 	}
 
-	return tks, nil
+	return nil
 }
 
 // NewLexer returns the default implementation of lexer.Lexer.
@@ -74,6 +177,11 @@ func (l *defaultLexer[T]) Tokenize(c lexer.Cursor) ([]T, error) {
 // # About the implementation
 //   - The priority is: ignore then lex-rules. And those rules are a ordered slice of LexicalRule.
 //   - When the character is not consumed by any lex-rule or ignored, the lexer.ErrUnexpectedChar is returned.
+//   - When a lex-rule fails, its error is returned. Both errors are wrapped into a lexer.Error with the position of the char.
+//   - When a lex-rule does not emit its token, the read bytes are omitted. If it did not read any byte either, the next lex-rule is tried.
+//   - When the Observer is set, it receives every tried rule, emitted token, omitted bytes and error.
+//   - The lexer is a lexer.Streamer too, which emits the tokens while it reads them. See ParseStream.
+//   - When the Warnings writer is set, the lex-rules are analyzed by AnalyzeLexer before returning the lexer.
 //
 // # Example
 //
@@ -102,6 +210,12 @@ func (l *defaultLexer[T]) Tokenize(c lexer.Cursor) ([]T, error) {
 //
 //	tks := l.Tokenize(NewCursor([]byte("123456789 4450048 777")))
 func NewLexer[T any](ops *LexerOptions[T]) lexer.Lexer[T] {
+	if ops.Warnings != nil {
+		for _, w := range AnalyzeLexer(ops) {
+			fmt.Fprintf(ops.Warnings, "lexer: %s\n", w.String())
+		}
+	}
+
 	return &defaultLexer[T]{
 		ops: ops,
 	}
@@ -114,6 +228,131 @@ func NewLexicalRule[T any](r ranges.ByteRange, t TokenRule[T]) LexicalRule[T] {
 	}
 }
 
+// NewFallibleTokenRule returns a FallibleTokenRule that always emits the token of t.
+func NewFallibleTokenRule[T any](t TokenRule[T]) FallibleTokenRule[T] {
+	return func(c lexer.Cursor, r ranges.ByteRange) (T, bool, error) {
+		return t(c, r), true, nil
+	}
+}
+
+// NewFallibleLexicalRule returns a LexicalRule for a FallibleTokenRule. Use as short-cut.
+// The default implementation of the Lexer omits the token when t does not emit it, and fails with the error of t. Where
+// the token rule of the LexicalRule only reports them to the Lexer, so it must be called by the Lexer, as the stream and
+// the parallel lexers do. Otherwise, it panics instead of emitting the token that was omitted or failed.
+//
+// # Example
+//
+//	func lexComment(c lexer.Cursor, r ranges.ByteRange) (*Token, bool, error) {
+//		for c.HasChar() && c.GetChar() != 0x0A {
+//			c.Next()
+//		}
+//		return nil, false, nil
+//	}
+//
+//	func lexStr(c lexer.Cursor, r ranges.ByteRange) (*Token, bool, error) {
+//		t := &Token{Type: "str"}
+//		c.Next()
+//		for c.HasChar() && !r(c.GetChar()) {
+//			t.Value = append(t.Value, c.GetChar())
+//			c.Next()
+//		}
+//		if !c.HasChar() {
+//			return nil, false, ErrUnterminatedString
+//		}
+//		c.Next()
+//		return t, true, nil
+//	}
+//
+//	rules := []LexicalRule[*Token]{
+//		NewFallibleLexicalRule(ranges.ByteSingle('#'), lexComment),
+//		NewFallibleLexicalRule(ranges.ByteSingle('"'), lexStr),
+//	}
+func NewFallibleLexicalRule[T any](r ranges.ByteRange, t FallibleTokenRule[T]) LexicalRule[T] {
+	f := func(c lexer.Cursor, r ranges.ByteRange) T {
+		tk, em, err := t(c, r)
+
+		rp, ok := c.(reporter)
+
+		switch {
+		case ok:
+			rp.report(em, err)
+		case err != nil:
+			panic(fmt.Errorf("aldana: a fallible token rule failed outside of the Lexer: %w", err))
+		case !em:
+			panic("aldana: a fallible token rule omitted its token outside of the Lexer")
+		}
+
+		return tk
+	}
+
+	return func() (ranges.ByteRange, TokenRule[T]) {
+		return r, f
+	}
+}
+
+// NewLookaheadLexicalRule returns a LexicalRule that fires when the current char is in the range r and the lookahead la is satisfied.
+// Otherwise, the next LexicalRule is tried. When the cursor does not implement lexer.Peeker, it fails with
+// lexer.ErrCursorCannotPeek.
+//
+// # Example
+//
+//	isFloat := func(p lexer.Peeker) bool {
+//		for i := 1; ; i++ {
+//			b, ok := p.Peek(i)
+//			if !ok || b < '0' || b > '9' {
+//				return ok && b == '.'
+//			}
+//		}
+//	}
+//
+//	rules := []LexicalRule[*Token]{
+//		NewLookaheadLexicalRule(NumRange, isFloat, NewFallibleTokenRule(lexFloat)),
+//		NewLexicalRule(NumRange, lexInt),
+//	}
+func NewLookaheadLexicalRule[T any](r ranges.ByteRange, la LexicalLookahead, t FallibleTokenRule[T]) LexicalRule[T] {
+	return NewFallibleLexicalRule(r, func(c lexer.Cursor, r ranges.ByteRange) (T, bool, error) {
+		pk, ok := c.(lexer.Peeker)
+		if !ok {
+			return *new(T), false, lexer.ErrCursorCannotPeek
+		}
+		if !la(pk) {
+			return *new(T), false, nil
+		}
+		return t(c, r)
+	})
+}
+
+// NewPrefixLexicalRule returns a LexicalRule that fires when the input continues with the bytes of p, as a
+// NewLookaheadLexicalRule. Otherwise, the next LexicalRule is tried. It panics when p is empty, because it cannot fire.
+//
+// # Example
+//
+//	rules := []LexicalRule[*Token]{
+//		NewPrefixLexicalRule("//", lexComment),
+//		NewPrefixLexicalRule("0x", NewFallibleTokenRule(lexHex)),
+//		NewLexicalRule(ranges.ByteSingle('/'), lexSlash),
+//		NewLexicalRule(NumRange, lexNumbs),
+//	}
+func NewPrefixLexicalRule[T any](p string, t FallibleTokenRule[T]) LexicalRule[T] {
+	if p == "" {
+		panic("aldana: NewPrefixLexicalRule with an empty prefix")
+	}
+
+	return NewLookaheadLexicalRule(ranges.ByteSingle(p[0]), HasPrefix(p), t)
+}
+
+// HasPrefix returns a LexicalLookahead that indicates whether the input continues with the bytes of p.
+func HasPrefix(p string) LexicalLookahead {
+	return func(pk lexer.Peeker) bool {
+		for i := 0; i < len(p); i++ {
+			if b, ok := pk.Peek(i); !ok || b != p[i] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // IgnoreWhiteSpaces returns a LexicalOmit for ignore the 0x20 (white-space).
 func IgnoreWhiteSpaces() LexicalOmit {
 	return func() (ranges.ByteRange, func(c lexer.Cursor, r ranges.ByteRange)) {
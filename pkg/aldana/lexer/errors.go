@@ -1,8 +1,29 @@
 package lexer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrUnexpectedChar is returned when a char is not expected for any lexical rule.
 	ErrUnexpectedChar = errors.New("unexpected char, cannot create or include in a token")
+	// ErrCursorCannotPeek is returned by a lookahead rule when the cursor does not implement Peeker.
+	ErrCursorCannotPeek = errors.New("the cursor cannot peek the chars")
 )
+
+// Error is a lexer-error positioned at the char where it happened.
+type Error struct {
+	// Err is the cause of the error.
+	Err error
+	// Position is the position of the char where the error happened.
+	Position Position
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at %s", e.Err, e.Position)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -1,5 +1,15 @@
 package lexer
 
+import "context"
+
+// Peeker provides a read-only view of the bytes ahead of a cursor. The cursors that implement it can be used with the
+// lookahead rules.
+type Peeker interface {
+	// Peek returns the char n positions after the current one, and a boolean that indicates whether it exists.
+	// Where Peek(0) is the current char.
+	Peek(n int) (byte, bool)
+}
+
 // Cursor provides a bytes reader.
 type Cursor interface {
 	// HasChar returns a boolean that indicates whether still bytes to read.
@@ -25,3 +35,10 @@ type Lexer[T any] interface {
 	// Tokenize returns a slice of tokens, or nil and a lexer-error.
 	Tokenize(c Cursor) ([]T, error)
 }
+
+// Streamer provides a lexer-processor that emits the tokens while it reads the input, so they can be analyzed before the end.
+// Where T is the type of the tokens.
+type Streamer[T any] interface {
+	// Stream calls emit with every token, in order. It stops with the error of emit, or the error of ctx when it is done.
+	Stream(ctx context.Context, c Cursor, emit func(t T) error) error
+}
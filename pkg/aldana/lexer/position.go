@@ -0,0 +1,45 @@
+package lexer
+
+import "fmt"
+
+// Position is a location of the input, with the same values as Cursor.GetPosition.
+type Position struct {
+	// Column is the column where the cursor is positioned.
+	Column int `json:"column"`
+	// Line is the line where the cursor is positioned.
+	Line int `json:"line"`
+}
+
+// String returns the position as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// PositionOf returns the Position of the current char of the cursor.
+// When the cursor has no chars left, the position is placed just after the last char.
+func PositionOf(c Cursor) Position {
+	cl, ln := c.GetPosition()
+
+	if !c.HasChar() {
+		cl += 1
+	}
+
+	return Position{Column: cl, Line: ln}
+}
+
+// Span is the range of the input between two positions. Where End is the position just after the last char.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// String returns the span as "line:column-line:column".
+func (s Span) String() string {
+	return fmt.Sprintf("%s-%s", s.Start, s.End)
+}
+
+// Spanned provides the Span where a token was read.
+type Spanned interface {
+	// GetSpan returns the Span where the token was read.
+	GetSpan() Span
+}
@@ -0,0 +1,178 @@
+package aldana
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+)
+
+// LexerWarningKind is the kind of a LexerWarning.
+type LexerWarningKind int
+
+const (
+	// OverlappedRules is reported when two lex-rules accept some equal first bytes.
+	OverlappedRules LexerWarningKind = iota
+	// ShadowedRule is reported when every first byte of a lex-rule is accepted before by the ignore or other lex-rules.
+	ShadowedRule
+	// UnacceptedBytes is reported for the bytes that neither the ignore nor any lex-rule accept.
+	UnacceptedBytes
+)
+
+// ignoreRule is the index of the ignore rule in a LexerWarning.
+const ignoreRule = -1
+
+// LexerWarning is a possible mistake found in the LexerOptions.
+type LexerWarning struct {
+	// Kind is the kind of the warning.
+	Kind LexerWarningKind
+	// Rules are the indexes of the involved LexicalRule. Where -1 is the ignore.
+	// For a ShadowedRule, the first one is the shadowed lex-rule.
+	Rules []int
+	// Bytes are the involved first bytes.
+	Bytes ranges.ByteTable
+}
+
+// String returns the warning as a readable message.
+func (w *LexerWarning) String() string {
+	switch w.Kind {
+	case OverlappedRules:
+		return fmt.Sprintf("%s and %s overlap on %s", ruleName(w.Rules[0]), ruleName(w.Rules[1]), w.Bytes.String())
+	case ShadowedRule:
+		if len(w.Rules) == 1 {
+			return fmt.Sprintf("%s never fires: it accepts no byte", ruleName(w.Rules[0]))
+		}
+		var n []string
+		for _, r := range w.Rules[1:] {
+			n = append(n, ruleName(r))
+		}
+		return fmt.Sprintf("%s is shadowed by %s on %s: it only fires when they yield", ruleName(w.Rules[0]), strings.Join(n, ", "), w.Bytes.String())
+	case UnacceptedBytes:
+		return fmt.Sprintf("no rule accepts %s", w.Bytes.String())
+	}
+	return ""
+}
+
+func ruleName(i int) string {
+	if i == ignoreRule {
+		return "the ignore"
+	}
+	return "rule " + strconv.Itoa(i)
+}
+
+// AnalyzeLexer returns the warnings about the first bytes accepted by the ignore and the lex-rules of ops.
+//
+// # About the analysis
+//   - The ranges are introspected by checking every byte, so they must not depend on anything but the byte.
+//   - The lex-rules that may yield, such as the ones made by NewPrefixLexicalRule, cannot be told apart from the others.
+//     So a ShadowedRule can be intended when the rules in front of it may yield.
+//
+// # Example
+//
+//	for _, w := range AnalyzeLexer(ops) {
+//		fmt.Println(w.String())
+//	}
+func AnalyzeLexer[T any](ops *LexerOptions[T]) []LexerWarning {
+	var (
+		ws   []LexerWarning
+		seen ranges.ByteTable
+		ig   ranges.ByteTable
+		tbs  = make([]ranges.ByteTable, len(ops.LexRules))
+		by   [256][]int
+	)
+
+	if ops.Ignore != nil {
+		r, _ := ops.Ignore()
+		ig = ranges.TableOf(r)
+		seen = ig
+		for i, ok := range seen {
+			if ok {
+				by[i] = append(by[i], ignoreRule)
+			}
+		}
+	}
+
+	for i, lr := range ops.LexRules {
+		r, _ := lr()
+		tbs[i] = ranges.TableOf(r)
+
+		if tbs[i].IsEmpty() {
+			ws = append(ws, LexerWarning{Kind: ShadowedRule, Rules: []int{i}})
+			continue
+		}
+
+		sh := true
+		for b, ok := range tbs[i] {
+			if ok && !seen[b] {
+				sh = false
+				break
+			}
+		}
+
+		if sh {
+			w := LexerWarning{Kind: ShadowedRule, Rules: []int{i}, Bytes: tbs[i]}
+			for j := ignoreRule; j < i; j++ {
+				for b, ok := range tbs[i] {
+					if ok && containsRule(by[b], j) {
+						w.Rules = append(w.Rules, j)
+						break
+					}
+				}
+			}
+			ws = append(ws, w)
+		} else {
+			for j := ignoreRule; j < i; j++ {
+				var (
+					ov    ranges.ByteTable
+					found bool
+					tb    = &ig
+				)
+				if j != ignoreRule {
+					tb = &tbs[j]
+				}
+				for b, ok := range tbs[i] {
+					if ok && tb[b] {
+						ov[b], found = true, true
+					}
+				}
+				if found {
+					ws = append(ws, LexerWarning{Kind: OverlappedRules, Rules: []int{j, i}, Bytes: ov})
+				}
+			}
+		}
+
+		for b, ok := range tbs[i] {
+			if ok {
+				seen[b] = true
+				by[b] = append(by[b], i)
+			}
+		}
+	}
+
+	var (
+		un    ranges.ByteTable
+		found bool
+	)
+
+	for b, ok := range seen {
+		if !ok {
+			un[b], found = true, true
+		}
+	}
+
+	if found {
+		ws = append(ws, LexerWarning{Kind: UnacceptedBytes, Bytes: un})
+	}
+
+	return ws
+}
+
+func containsRule(rs []int, r int) bool {
+	for _, v := range rs {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
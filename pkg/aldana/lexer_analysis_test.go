@@ -0,0 +1,76 @@
+package aldana
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRangeRule(r ranges.ByteRange) LexicalRule[*token] {
+	return NewLexicalRule(r, func(c lexer.Cursor, r ranges.ByteRange) *token {
+		c.Next()
+		return &token{}
+	})
+}
+
+/*
+Given: lex-rules with overlapped, shadowed and empty ranges.
+When: analyzes the lexer options.
+Then: returns a warning for each one, and for the unaccepted bytes.
+*/
+func TestAnalyzeLexer(t *testing.T) {
+	// arrange
+	ops := &LexerOptions[*token]{
+		Ignore: IgnoreWhiteSpaces(),
+		LexRules: []LexicalRule[*token]{
+			mockRangeRule(ranges.ByteBounded(0x30, 0x39)),
+			mockRangeRule(ranges.ByteBounded(0x61, 0x7A)),
+			mockRangeRule(ranges.ByteSet('x', '0')),
+			mockRangeRule(ranges.RangeByteOfRange(ranges.ByteBounded(0x20, 0x2F), ranges.ByteBounded(0x3A, 0x60), ranges.ByteBounded(0x7B, 0xFF))),
+			mockRangeRule(ranges.ByteSet()),
+		},
+	}
+
+	// act
+	ws := AnalyzeLexer(ops)
+
+	// assert
+	var msg []string
+	for _, w := range ws {
+		msg = append(msg, w.String())
+	}
+
+	assert.Equal(t, []string{
+		"rule 2 is shadowed by rule 0, rule 1 on '0', 'x': it only fires when they yield",
+		"the ignore and rule 3 overlap on 0x20",
+		"rule 4 never fires: it accepts no byte",
+		"no rule accepts 0x00-0x1F",
+	}, msg)
+	assert.Equal(t, []int{2, 0, 1}, ws[0].Rules)
+	assert.Equal(t, ShadowedRule, ws[0].Kind)
+}
+
+/*
+Given: lexer options with a warnings writer.
+When: creates the lexer.
+Then: writes a line per warning.
+*/
+func TestNewLexer_with_warnings(t *testing.T) {
+	// arrange
+	var b bytes.Buffer
+
+	// act
+	NewLexer(&LexerOptions[*token]{
+		LexRules: []LexicalRule[*token]{
+			mockRangeRule(ranges.ByteBounded(0x00, 0xFF)),
+			mockLexerRule(),
+		},
+		Warnings: &b,
+	})
+
+	// assert
+	assert.Equal(t, "lexer: rule 1 is shadowed by rule 0 on '0'-'9': it only fires when they yield\n", b.String())
+}
@@ -1,6 +1,7 @@
 package aldana
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
@@ -102,6 +103,211 @@ func TestLexer_Tokenize_with_only_acceptable_chars(t *testing.T) {
 	})
 }
 
+/*
+Given: fallible lex-rules that drop comments and fail on unterminated strings.
+When: tokenizes the chars.
+Then: omits the comments, or returns the positioned error and no tokens.
+*/
+func TestLexer_Tokenize_with_fallible_rules(t *testing.T) {
+	t.Run("dropped token", func(t *testing.T) {
+		// arrange
+		lex := setUpFallibleLexer()
+
+		// act
+		tks, err := lex.Tokenize(mockCursor([]byte("12 #34 56")))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, tks, 2)
+		assert.Equal(t, "12", string(tks[0].Value))
+		assert.Equal(t, "56", string(tks[1].Value))
+	})
+
+	t.Run("failed token", func(t *testing.T) {
+		// arrange
+		lex := setUpFallibleLexer()
+
+		// act
+		tks, err := lex.Tokenize(mockCursor([]byte("12 \"34")))
+
+		// assert
+		assert.ErrorIs(t, err, errUnterminated)
+
+		var lErr *lexer.Error
+		assert.ErrorAs(t, err, &lErr)
+		assert.Equal(t, lexer.Position{Column: 4}, lErr.Position)
+		assert.Nil(t, tks)
+	})
+
+	t.Run("yielded rule", func(t *testing.T) {
+		// arrange
+		lex := setUpFallibleLexer()
+
+		// act
+		tks, err := lex.Tokenize(mockCursor([]byte("1 #")))
+
+		// assert
+		assert.ErrorIs(t, err, lexer.ErrUnexpectedChar)
+		assert.Nil(t, tks)
+	})
+}
+
+/*
+Given: the token rules of fallible lex-rules, and a cursor that does not come from the Lexer.
+When: the token rules are called with the cursor.
+Then: returns the emitted token, otherwise panics instead of losing the omission or the error.
+*/
+func TestNewFallibleLexicalRule_outside_of_the_lexer(t *testing.T) {
+	// arrange
+	ops := fallibleLexerOptions()
+	_, comment := ops.LexRules[1]()
+	quote, str := ops.LexRules[2]()
+
+	// act
+	c := mockCursor([]byte("\"34\""))
+	c.Next()
+	tk := str(c, quote)
+
+	// assert
+	assert.Equal(t, "34", string(tk.Value))
+	assert.PanicsWithValue(t, "aldana: a fallible token rule omitted its token outside of the Lexer", func() {
+		c := mockCursor([]byte("#34"))
+		c.Next()
+		comment(c, ranges.ByteSingle('#'))
+	})
+	assert.Panics(t, func() {
+		c := mockCursor([]byte("\"34"))
+		c.Next()
+		str(c, quote)
+	})
+}
+
+/*
+Given: prefix lex-rules that share the first byte with other lex-rules.
+When: tokenizes the chars.
+Then: each lex-rule fires only when its prefix is found.
+*/
+func TestLexer_Tokenize_with_prefix_rules(t *testing.T) {
+	// arrange
+	lexAs := func(ty string, n int) TokenRule[*token] {
+		return func(c lexer.Cursor, r ranges.ByteRange) *token {
+			t := &token{Type: ty}
+			for i := 0; i < n; i++ {
+				t.Value = append(t.Value, c.GetChar())
+				c.Next()
+			}
+			return t
+		}
+	}
+	lex := NewLexer(&LexerOptions[*token]{
+		Ignore: IgnoreWhiteSpaces(),
+		LexRules: []LexicalRule[*token]{
+			NewPrefixLexicalRule("//", func(c lexer.Cursor, r ranges.ByteRange) (*token, bool, error) {
+				for c.HasChar() {
+					c.Next()
+				}
+				return nil, false, nil
+			}),
+			NewPrefixLexicalRule("...", NewFallibleTokenRule(lexAs("spread", 3))),
+			NewLookaheadLexicalRule(ranges.ByteSingle('.'), func(p lexer.Peeker) bool {
+				b, ok := p.Peek(1)
+				return ok && b >= '0' && b <= '9'
+			}, NewFallibleTokenRule(lexAs("fraction", 2))),
+			NewLexicalRule(ranges.ByteSet('/', '.'), lexAs("op", 1)),
+			mockLexerRule(),
+		},
+	})
+
+	// act
+	tks, err := lex.Tokenize(mockCursor([]byte("1 / ... .5 .. // 2")))
+
+	// assert
+	assert.NoError(t, err)
+
+	var ty []string
+	for _, tk := range tks {
+		ty = append(ty, tk.Type+":"+string(tk.Value))
+	}
+	assert.Equal(t, []string{"num:1", "op:/", "spread:...", "fraction:.5", "op:.", "op:."}, ty)
+}
+
+// noPeekCursor hides the Peek of a cursor.
+type noPeekCursor struct {
+	lexer.Cursor
+}
+
+/*
+Given: lookahead lex-rules.
+When: tokenizes the chars of a cursor that cannot peek, or makes a rule of an empty prefix.
+Then: returns the positioned lexer.ErrCursorCannotPeek, or panics.
+*/
+func TestLexer_Tokenize_with_prefix_rules_and_no_peek(t *testing.T) {
+	t.Run("cursor without peek", func(t *testing.T) {
+		// arrange
+		lex := NewLexer(&LexerOptions[*token]{
+			LexRules: []LexicalRule[*token]{
+				NewPrefixLexicalRule("12", NewFallibleTokenRule(mockLexerRuleToken())),
+			},
+		})
+
+		// act
+		tks, err := lex.Tokenize(noPeekCursor{mockCursor([]byte("123"))})
+
+		// assert
+		assert.ErrorIs(t, err, lexer.ErrCursorCannotPeek)
+		assert.Nil(t, tks)
+	})
+
+	t.Run("empty prefix", func(t *testing.T) {
+		// act & assert
+		assert.PanicsWithValue(t, "aldana: NewPrefixLexicalRule with an empty prefix", func() {
+			NewPrefixLexicalRule("", NewFallibleTokenRule(mockLexerRuleToken()))
+		})
+	})
+}
+
+func mockLexerRuleToken() TokenRule[*token] {
+	_, t := mockLexerRule()()
+	return t
+}
+
+var errUnterminated = errors.New("unterminated string")
+
+func setUpFallibleLexer() lexer.Lexer[*token] {
+	return NewLexer(fallibleLexerOptions())
+}
+
+func fallibleLexerOptions() *LexerOptions[*token] {
+	return &LexerOptions[*token]{
+		Ignore: IgnoreWhiteSpaces(),
+		LexRules: []LexicalRule[*token]{
+			mockLexerRule(),
+			NewFallibleLexicalRule(ranges.ByteSingle('#'), func(c lexer.Cursor, r ranges.ByteRange) (*token, bool, error) {
+				if p, _ := c.GetPosition(); p == 3 {
+					return nil, false, nil
+				}
+				for c.HasChar() && c.GetChar() != 0x20 {
+					c.Next()
+				}
+				return nil, false, nil
+			}),
+			NewFallibleLexicalRule(ranges.ByteSingle('"'), func(c lexer.Cursor, r ranges.ByteRange) (*token, bool, error) {
+				t := &token{Type: "str"}
+				c.Next()
+				for c.HasChar() && !r(c.GetChar()) {
+					t.Value = append(t.Value, c.GetChar())
+					c.Next()
+				}
+				if !c.HasChar() {
+					return nil, false, errUnterminated
+				}
+				c.Next()
+				return t, true, nil
+			}),
+		},
+	}
+}
+
 func mockCursor(b []byte) lexer.Cursor {
 	return NewCursor(b)
 }
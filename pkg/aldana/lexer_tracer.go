@@ -0,0 +1,123 @@
+package aldana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+)
+
+// LexerObserver receives the events of the default implementation of the Lexer.
+// Where T is the type of the tokens.
+type LexerObserver[T any] interface {
+	// RuleTried is called after the LexicalRule at index i was checked against the char b at p.
+	RuleTried(i int, b byte, p lexer.Position, ok bool)
+	// TokenEmitted is called when the LexicalRule at index i emits the token t, read from s to e.
+	TokenEmitted(i int, t T, s lexer.Position, e lexer.Position)
+	// BytesOmitted is called when the Ignore rule omits the bytes from s to e.
+	BytesOmitted(s lexer.Position, e lexer.Position)
+	// Error is called when the lexer stops with the error err at p.
+	Error(err error, p lexer.Position)
+}
+
+// TraceFormat is the output format of the tracers.
+type TraceFormat int
+
+const (
+	// TraceText writes a human-readable line per event.
+	TraceText TraceFormat = iota
+	// TraceJSON writes a JSON object per line and event.
+	TraceJSON
+	// TraceDOT writes a Graphviz graph. Only for the ParseTracer, the lexer tracer writes it as TraceText.
+	TraceDOT
+)
+
+// nopLexerObserver implements LexerObserver by doing nothing.
+type nopLexerObserver[T any] struct{}
+
+func (nopLexerObserver[T]) RuleTried(int, byte, lexer.Position, bool)           {}
+func (nopLexerObserver[T]) TokenEmitted(int, T, lexer.Position, lexer.Position) {}
+func (nopLexerObserver[T]) BytesOmitted(lexer.Position, lexer.Position)         {}
+func (nopLexerObserver[T]) Error(error, lexer.Position)                         {}
+
+// lexerTraceEvent is the JSON representation of a lexer event.
+type lexerTraceEvent struct {
+	Event   string          `json:"event"`
+	Rule    *int            `json:"rule,omitempty"`
+	Char    string          `json:"char,omitempty"`
+	Matched *bool           `json:"matched,omitempty"`
+	Token   string          `json:"token,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Start   lexer.Position  `json:"start"`
+	End     *lexer.Position `json:"end,omitempty"`
+}
+
+// lexerTracer implements LexerObserver by writing the events into w.
+type lexerTracer[T any] struct {
+	w io.Writer
+	f TraceFormat
+}
+
+func (t *lexerTracer[T]) RuleTried(i int, b byte, p lexer.Position, ok bool) {
+	if t.f == TraceJSON {
+		t.encode(lexerTraceEvent{Event: "try", Rule: &i, Char: string(b), Matched: &ok, Start: p})
+		return
+	}
+
+	r := "rejected"
+	if ok {
+		r = "matched"
+	}
+	fmt.Fprintf(t.w, "%s try rule %d on %q: %s\n", p, i, b, r)
+}
+
+func (t *lexerTracer[T]) TokenEmitted(i int, tk T, s lexer.Position, e lexer.Position) {
+	if t.f == TraceJSON {
+		t.encode(lexerTraceEvent{Event: "emit", Rule: &i, Token: fmt.Sprint(tk), Start: s, End: &e})
+		return
+	}
+	fmt.Fprintf(t.w, "%s-%s emit rule %d: %v\n", s, e, i, tk)
+}
+
+func (t *lexerTracer[T]) BytesOmitted(s lexer.Position, e lexer.Position) {
+	if t.f == TraceJSON {
+		t.encode(lexerTraceEvent{Event: "omit", Start: s, End: &e})
+		return
+	}
+	fmt.Fprintf(t.w, "%s-%s omit\n", s, e)
+}
+
+func (t *lexerTracer[T]) Error(err error, p lexer.Position) {
+	if t.f == TraceJSON {
+		t.encode(lexerTraceEvent{Event: "error", Error: err.Error(), Start: p})
+		return
+	}
+	fmt.Fprintf(t.w, "%s error: %s\n", p, err)
+}
+
+func (t *lexerTracer[T]) encode(e lexerTraceEvent) {
+	b, err := json.Marshal(e)
+
+	if err != nil {
+		return
+	}
+
+	t.w.Write(append(b, '\n'))
+}
+
+// NewLexerTracer returns a LexerObserver that writes every event of the lexer into w, using the format f.
+//
+// # Example
+//
+//	l := NewLexer(&LexerOptions[*Token]{
+//		Ignore:   IgnoreWhiteSpaces(),
+//		LexRules: []LexicalRule[*Token]{NewLexicalRule(ranges.ByteBounded(0x30, 0x39), lexNumbs)},
+//		Observer: NewLexerTracer[*Token](os.Stderr, TraceText),
+//	})
+func NewLexerTracer[T any](w io.Writer, f TraceFormat) LexerObserver[T] {
+	return &lexerTracer[T]{
+		w: w,
+		f: f,
+	}
+}
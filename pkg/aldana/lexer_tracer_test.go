@@ -0,0 +1,117 @@
+package aldana
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedEvent struct {
+	Kind  string
+	Rule  int
+	Start lexer.Position
+	End   lexer.Position
+}
+
+type recordingObserver struct {
+	events []recordedEvent
+}
+
+func (o *recordingObserver) RuleTried(i int, _ byte, p lexer.Position, ok bool) {
+	if ok {
+		o.events = append(o.events, recordedEvent{Kind: "try", Rule: i, Start: p})
+	}
+}
+
+func (o *recordingObserver) TokenEmitted(i int, _ *token, s lexer.Position, e lexer.Position) {
+	o.events = append(o.events, recordedEvent{Kind: "emit", Rule: i, Start: s, End: e})
+}
+
+func (o *recordingObserver) BytesOmitted(s lexer.Position, e lexer.Position) {
+	o.events = append(o.events, recordedEvent{Kind: "omit", Start: s, End: e})
+}
+
+func (o *recordingObserver) Error(_ error, p lexer.Position) {
+	o.events = append(o.events, recordedEvent{Kind: "error", Start: p})
+}
+
+/*
+Given: an observer and a cursor with acceptable and ignorable chars.
+When: tokenizes the chars.
+Then: the observer receives every event with its positions.
+*/
+func TestLexer_Tokenize_with_observer(t *testing.T) {
+	// arrange
+	obs := &recordingObserver{}
+	lex := NewLexer(&LexerOptions[*token]{
+		Ignore:   IgnoreWhiteSpaces(),
+		LexRules: []LexicalRule[*token]{mockLexerRule()},
+		Observer: obs,
+	})
+
+	// act
+	_, err := lex.Tokenize(mockCursor([]byte("12 3")))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []recordedEvent{
+		{Kind: "try", Rule: 0, Start: lexer.Position{Column: 1}},
+		{Kind: "emit", Rule: 0, Start: lexer.Position{Column: 1}, End: lexer.Position{Column: 3}},
+		{Kind: "omit", Start: lexer.Position{Column: 3}, End: lexer.Position{Column: 4}},
+		{Kind: "try", Rule: 0, Start: lexer.Position{Column: 4}},
+		{Kind: "emit", Rule: 0, Start: lexer.Position{Column: 4}, End: lexer.Position{Column: 5}},
+	}, obs.events)
+}
+
+/*
+Given: a tracer and a cursor with a non acceptable char.
+When: tokenizes the chars.
+Then: writes the events, ending with the error.
+*/
+func TestLexerTracer(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		// arrange
+		var b bytes.Buffer
+		lex := NewLexer(&LexerOptions[*token]{
+			LexRules: []LexicalRule[*token]{mockLexerRule()},
+			Observer: NewLexerTracer[*token](&b, TraceText),
+		})
+
+		// act
+		_, err := lex.Tokenize(mockCursor([]byte("1A")))
+
+		// assert
+		assert.ErrorIs(t, err, lexer.ErrUnexpectedChar)
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		assert.Len(t, lines, 4)
+		assert.Equal(t, "0:1 try rule 0 on '1': matched", lines[0])
+		assert.Equal(t, "0:2 try rule 0 on 'A': rejected", lines[2])
+		assert.True(t, strings.HasPrefix(lines[3], "0:2 error: "))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// arrange
+		var b bytes.Buffer
+		lex := NewLexer(&LexerOptions[*token]{
+			LexRules: []LexicalRule[*token]{mockLexerRule()},
+			Observer: NewLexerTracer[*token](&b, TraceJSON),
+		})
+
+		// act
+		_, err := lex.Tokenize(mockCursor([]byte("1")))
+
+		// assert
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		assert.Len(t, lines, 2)
+
+		var ev map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &ev))
+		assert.Equal(t, "emit", ev["event"])
+		assert.Equal(t, map[string]any{"column": 2.0, "line": 0.0}, ev["end"])
+	})
+}
@@ -0,0 +1,7 @@
+package lr
+
+import "errors"
+
+var (
+	ErrCyclicReductions = errors.New("the reductions do not end before the token")
+)
@@ -0,0 +1,183 @@
+package lr
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/grammartest"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpTable(t *testing.T, src string) *Table {
+	g, err := grammar.Parse(src)
+	assert.NoError(t, err)
+	tb, err := NewTable(g, "")
+	assert.NoError(t, err)
+	return tb
+}
+
+/*
+Given: a left recursive grammar.
+When: parses acceptable tokens by its table.
+Then: returns the tree grouped by the left recursion and no conflicts.
+*/
+func TestNewParser_with_left_recursion(t *testing.T) {
+	// arrange
+	tb := setUpTable(t, `
+		expr   = expr "+" term | term ;
+		term   = term "*" factor | factor ;
+		factor = NUMBER | "(" expr ")" ;
+	`)
+	prs := NewParser(tb, grammartest.Options())
+
+	// act
+	tr, err := prs.Parse(aldana.NewReader([]string{"1", "+", "2", "*", "3", "+", "(", "4", ")"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Empty(t, tb.Conflicts)
+	assert.Equal(t, "(expr (expr (expr (term (factor 1))) + (term (term (factor 2)) * (factor 3))) + (term (factor ( (expr (term (factor 4))) ))))", tr.String())
+}
+
+/*
+Given: a grammar with EBNF expressions.
+When: parses acceptable tokens by its table.
+Then: returns the tree where the nodes of the EBNF expressions belong to their production.
+*/
+func TestNewParser_with_ebnf(t *testing.T) {
+	// arrange
+	tb := setUpTable(t, `
+		program = decl+ ;
+		decl    = "let" IDENT [ "=" value ] ";" | IDENT "=" value ";" ;
+		value   = NUMBER | IDENT | "[" [ value { "," value } ] "]" ;
+	`)
+	prs := NewParser(tb, grammartest.Options())
+
+	// act
+	tr, err := prs.Parse(aldana.NewReader([]string{"let", "a", ";", "b", "=", "[", "1", ",", "b", ",", "2", "]", ";"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Empty(t, tb.Conflicts)
+	assert.Equal(t, "(program (decl let a ;) (decl b = (value [ (value 1) , (value b) , (value 2) ]) ;))", tr.String())
+}
+
+/*
+Given: a table of a grammar.
+When: parses non acceptable tokens.
+Then: returns a parser.SyntaxError with the terminals expected by the state.
+*/
+func TestNewParser_with_non_acceptable_tokens(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		expect string
+	}{
+		"unexpected token": {[]string{"1", "+", "*"}, "expected '(' or NUMBER but found *"},
+		"unexpected end":   {[]string{"(", "1"}, "expected '+', '*' or ')' but found the end of the tokens"},
+		"unexpected rest":  {[]string{"1", ")"}, "expected '+', '*' or end of the tokens but found )"},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// arrange
+			prs := NewParser(setUpTable(t, `
+				expr   = expr "+" term | term ;
+				term   = term "*" factor | factor ;
+				factor = NUMBER | "(" expr ")" ;
+			`), grammartest.Options())
+
+			// act
+			_, err := prs.Parse(aldana.NewReader(c.tokens))
+
+			// assert
+			var sErr *parser.SyntaxError
+			assert.ErrorAs(t, err, &sErr)
+			assert.Equal(t, c.expect, sErr.Error())
+		})
+	}
+}
+
+/*
+Given: ambiguous grammars.
+When: builds their tables.
+Then: returns the conflicts with an example input, resolved as yacc does.
+*/
+func TestNewTable_with_conflicts(t *testing.T) {
+	t.Run("shift/reduce", func(t *testing.T) {
+		// arrange
+		tb := setUpTable(t, `expr = expr "+" expr | NUMBER ;`)
+
+		// act
+		tr, err := NewParser(tb, grammartest.Options()).Parse(aldana.NewReader([]string{"1", "+", "2", "+", "3"}))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, tb.Conflicts, 1)
+		assert.Equal(t, ShiftReduce, tb.Conflicts[0].Kind)
+		assert.Equal(t, `shift/reduce conflict on "+", for example: NUMBER "+" NUMBER . "+"
+	chosen: expr = expr . "+" expr
+	discarded: expr = expr "+" expr .`, tb.Conflicts[0].String())
+		assert.Equal(t, "(expr (expr 1) + (expr (expr 2) + (expr 3)))", tr.String())
+	})
+
+	t.Run("reduce/reduce", func(t *testing.T) {
+		// arrange
+		tb := setUpTable(t, `start = a | b ; a = IDENT ; b = IDENT ;`)
+
+		// act
+		tr, err := NewParser(tb, grammartest.Options()).Parse(aldana.NewReader([]string{"x"}))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, tb.Conflicts, 1)
+		assert.Equal(t, ReduceReduce, tb.Conflicts[0].Kind)
+		assert.Equal(t, []string{"IDENT", "$"}, tb.Conflicts[0].Example)
+		assert.Equal(t, "a = IDENT .", tb.Conflicts[0].Chosen)
+		assert.Equal(t, []string{"b = IDENT ."}, tb.Conflicts[0].Discarded)
+		assert.Equal(t, "(start (a x))", tr.String())
+	})
+}
+
+/*
+Given: a grammar that refers to an undefined production.
+When: builds its table.
+Then: returns ErrUndefinedProduction.
+*/
+func TestNewTable_with_undefined_production(t *testing.T) {
+	// arrange
+	g, _ := grammar.Parse(`expr = term "+" term ;`)
+
+	// act
+	_, err := NewTable(g, "")
+
+	// assert
+	assert.ErrorIs(t, err, grammar.ErrUndefinedProduction)
+}
+
+/*
+Given: grammars whose repetitions are nullable.
+When: parses tokens by their tables.
+Then: returns an ErrCyclicReductions instead of looping forever.
+*/
+func TestNewParser_with_nullable_repetitions(t *testing.T) {
+	tests := map[string]string{
+		"repeated braces":   `a = { "x" }* ;`,
+		"repeated optional": `a = ("x"?)* ;`,
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			tb := setUpTable(t, src)
+			prs := NewParser(tb, grammartest.Options())
+
+			// act
+			_, err := prs.Parse(aldana.NewReader([]string{"x"}))
+
+			// assert
+			assert.ErrorIs(t, err, ErrCyclicReductions)
+		})
+	}
+}
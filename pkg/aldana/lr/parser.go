@@ -0,0 +1,152 @@
+package lr
+
+import (
+	"fmt"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/bnf"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// lrParser implements parser.Parser by a LALR(1) table.
+type lrParser[Tt any, Tn any] struct {
+	t *Table
+	// preds are the predicates of the terminals, indexed by symbol.
+	preds []aldana.TokenPredicate[Tt]
+	// acts are the actions of the non-terminals that are not helpers, indexed by symbol.
+	acts []grammar.Action[Tn]
+	leaf func(t Tt) Tn
+}
+
+// terminal returns the terminal of the token t, or of the end of the tokens when ok is false, among the terminals of the state s.
+func (p *lrParser[Tt, Tn]) terminal(s *state, t Tt, ok bool) (int, bool) {
+	if !ok {
+		_, has := s.actions[bnf.End]
+		return bnf.End, has
+	}
+
+	for _, x := range s.terms {
+		if x != bnf.End && p.preds[x](t) {
+			return x, true
+		}
+	}
+
+	return 0, false
+}
+
+// bound returns the most reductions that end without a shift from the n states of the stack.
+// Without shifting, the stack grows only by the empty productions, up to a state each, and between the pushes and the pops
+// the unit productions chain up to a non-terminal each. So more reductions mean a cycle of them.
+func (p *lrParser[Tt, Tn]) bound(n int) int {
+	return (2*(n+len(p.t.states)) + 1) * (len(p.t.bnf.Syms) + 1)
+}
+
+func (p *lrParser[Tt, Tn]) Parse(r parser.Reader[Tt]) (Tn, error) {
+	r.Next()
+
+	states := []int{0}
+	values := [][]Tn{}
+
+	// base are the states before the reductions of the current token, which are used for report the expected terminals.
+	base := []int{0}
+
+	// reduced counts the reductions since the last shift, which are bounded by bound.
+	reduced := 0
+
+	for {
+		s := p.t.states[states[len(states)-1]]
+		t, ok := r.Peek(0)
+		x, found := p.terminal(s, t, ok)
+
+		if !found {
+			l := p.t.expected(base)
+
+			if !ok {
+				return *new(Tn), parser.NewSyntaxError(nil, r.Position(), l...)
+			}
+
+			return *new(Tn), parser.NewSyntaxError(t, r.Position(), l...)
+		}
+
+		a := s.actions[x]
+
+		switch a.kind {
+		case shiftAction:
+			states = append(states, a.n)
+			values = append(values, []Tn{p.leaf(t)})
+			base = append(base[:0], states...)
+			reduced = 0
+			r.Next()
+		case reduceAction:
+			if reduced++; reduced > p.bound(len(base)) {
+				return *new(Tn), fmt.Errorf("%w: %d", ErrCyclicReductions, r.Position())
+			}
+
+			pr := p.t.bnf.Prods[a.n]
+			n := len(values) - len(pr.RHS)
+
+			var nds []Tn
+			for _, v := range values[n:] {
+				nds = append(nds, v...)
+			}
+
+			if act := p.acts[pr.LHS]; act != nil {
+				nds = []Tn{act(nds)}
+			}
+
+			values = append(values[:n], nds)
+			states = states[:len(states)-len(pr.RHS)]
+			states = append(states, p.t.states[states[len(states)-1]].next[pr.LHS])
+		case acceptAction:
+			return values[0][0], nil
+		}
+	}
+}
+
+// NewParser returns a parser.Parser driven by the table t, which matches the tokens and builds the nodes by the options ops.
+// The Rules and Start of the options are not used, the table already has its start.
+//
+// # About the implementation
+//   - The terminal of a token is chosen among the terminals expected by the current state, where the literals are tried before
+//     the kinds. So keywords can be kinds too, such as "let" and IDENT.
+//   - The nodes of a production are made by its action, with the nodes of its matched terminals and non-terminals.
+//   - When the token is not expected, a parser.SyntaxError is returned with all the terminals that can be shifted after the
+//     last shifted token. So the ones expected before the reductions are included.
+//   - When the reductions of a token do not end, as the ones of the nullable repetitions like { "x" }*, Parse returns an
+//     ErrCyclicReductions with the position of the token instead of looping forever.
+//
+// # Example
+//
+//	t, _ := NewTable(g, "")
+//
+//	prs := NewParser(t, &grammar.ParserOptions[*token.Token[string], *Node]{
+//		Literal: IsRaw,
+//		Kind:    IsKind,
+//		Leaf:    NewLeafNode,
+//		Actions: map[string]grammar.Action[*Node]{
+//			"expr": NewBinaryNode,
+//		},
+//	})
+func NewParser[Tt any, Tn any](t *Table, ops *grammar.ParserOptions[Tt, Tn]) parser.Parser[Tt, Tn] {
+	p := &lrParser[Tt, Tn]{
+		t:     t,
+		preds: make([]aldana.TokenPredicate[Tt], len(t.bnf.Syms)),
+		acts:  make([]grammar.Action[Tn], len(t.bnf.Syms)),
+		leaf:  ops.Leaf,
+	}
+
+	for i, s := range t.bnf.Syms {
+		switch {
+		case i == bnf.End:
+		case s.Terminal && s.Literal:
+			p.preds[i] = ops.Literal(s.Value)
+		case s.Terminal:
+			p.preds[i] = ops.Kind(s.Value)
+		case !s.Helper:
+			p.acts[i] = ops.Action(s.Name)
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,449 @@
+package lr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/grammar"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/internal/bnf"
+)
+
+// ConflictKind is the kind of a conflict of the table.
+type ConflictKind int
+
+const (
+	// ShiftReduce is a conflict between shifting the terminal and reducing a production.
+	ShiftReduce ConflictKind = iota
+	// ReduceReduce is a conflict between reducing two or more productions.
+	ReduceReduce
+)
+
+func (k ConflictKind) String() string {
+	if k == ShiftReduce {
+		return "shift/reduce"
+	}
+	return "reduce/reduce"
+}
+
+// Conflict is a state where more than one action can be taken for the same terminal.
+// The conflicts are resolved as yacc does: the shift is preferred to the reduce, and the earlier production to the later.
+type Conflict struct {
+	Kind ConflictKind
+	// State is the index of the state of the conflict.
+	State int
+	// Terminal is the name of the terminal of the conflict.
+	Terminal string
+	// Chosen is the item of the taken action: the shifted item or the reduced production.
+	Chosen string
+	// Discarded are the items of the discarded reduces.
+	Discarded []string
+	// Example is the shortest input that reaches the conflict, where the last one is the terminal of the conflict.
+	Example []string
+}
+
+func (c *Conflict) String() string {
+	var b strings.Builder
+
+	n := len(c.Example) - 1
+
+	fmt.Fprintf(&b, "%s conflict on %s, for example: %s", c.Kind, c.Terminal, strings.Join(append(c.Example[:n:n], ".", c.Example[n]), " "))
+	fmt.Fprintf(&b, "\n\tchosen: %s", c.Chosen)
+
+	for _, d := range c.Discarded {
+		fmt.Fprintf(&b, "\n\tdiscarded: %s", d)
+	}
+
+	return b.String()
+}
+
+// actionKind is the kind of an action of the table.
+type actionKind int
+
+const (
+	shiftAction actionKind = iota
+	reduceAction
+	acceptAction
+)
+
+// action is an action of the table. Where n is the target state of the shifts, or the production of the reduces.
+type action struct {
+	kind actionKind
+	n    int
+}
+
+// item is a production with a dot before the symbol d.
+type item struct {
+	prod int
+	dot  int
+}
+
+// state is a state of the automaton.
+type state struct {
+	// items are the kernel items, followed by their closure.
+	items []item
+	index map[item]int
+	// la are the lookaheads of the items.
+	la []map[int]bool
+	// next are the target states of the symbols.
+	next map[int]int
+	// actions are the actions of the terminals.
+	actions map[int]action
+	// terms are the terminals of the actions, where the literals are before the kinds.
+	terms []int
+}
+
+// Table is a LALR(1) parse table built from a grammar.
+type Table struct {
+	bnf    *bnf.BNF
+	states []*state
+	// Conflicts are the conflicts found while building the table, already resolved.
+	Conflicts []Conflict
+}
+
+// closure returns the items of the kernel k followed by the items of the productions after their dots.
+func (t *Table) closure(k []item) *state {
+	s := &state{index: map[item]int{}, next: map[int]int{}, actions: map[int]action{}}
+
+	add := func(it item) {
+		if _, ok := s.index[it]; !ok {
+			s.index[it] = len(s.items)
+			s.items = append(s.items, it)
+		}
+	}
+
+	for _, it := range k {
+		add(it)
+	}
+
+	for i := 0; i < len(s.items); i++ {
+		p := t.bnf.Prods[s.items[i].prod]
+
+		if s.items[i].dot == len(p.RHS) {
+			continue
+		}
+
+		for _, q := range t.bnf.Syms[p.RHS[s.items[i].dot]].Prods {
+			add(item{prod: q})
+		}
+	}
+
+	s.la = make([]map[int]bool, len(s.items))
+	for i := range s.la {
+		s.la[i] = map[int]bool{}
+	}
+
+	return s
+}
+
+func kernelKey(k []item) string {
+	s := make([]string, len(k))
+	for i, it := range k {
+		s[i] = strconv.Itoa(it.prod) + "." + strconv.Itoa(it.dot)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+// buildStates builds the LR(0) automaton.
+func (t *Table) buildStates() {
+	keys := map[string]int{}
+
+	k := []item{{prod: 0}}
+	t.states = []*state{t.closure(k)}
+	keys[kernelKey(k)] = 0
+
+	for i := 0; i < len(t.states); i++ {
+		s := t.states[i]
+
+		var syms []int
+		kernels := map[int][]item{}
+
+		for _, it := range s.items {
+			p := t.bnf.Prods[it.prod]
+
+			if it.dot == len(p.RHS) {
+				continue
+			}
+
+			x := p.RHS[it.dot]
+
+			if _, ok := kernels[x]; !ok {
+				syms = append(syms, x)
+			}
+
+			kernels[x] = append(kernels[x], item{prod: it.prod, dot: it.dot + 1})
+		}
+
+		for _, x := range syms {
+			key := kernelKey(kernels[x])
+			j, ok := keys[key]
+
+			if !ok {
+				j = len(t.states)
+				keys[key] = j
+				t.states = append(t.states, t.closure(kernels[x]))
+			}
+
+			s.next[x] = j
+		}
+	}
+}
+
+// propagate computes the LALR(1) lookaheads of the items, by propagating them until no one changes.
+func (t *Table) propagate() {
+	t.states[0].la[0][bnf.End] = true
+
+	union := func(dst map[int]bool, src map[int]bool) bool {
+		changed := false
+		for x := range src {
+			if !dst[x] {
+				dst[x], changed = true, true
+			}
+		}
+		return changed
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, s := range t.states {
+			for i, it := range s.items {
+				p := t.bnf.Prods[it.prod]
+
+				if it.dot == len(p.RHS) {
+					continue
+				}
+
+				x := p.RHS[it.dot]
+
+				if !t.bnf.Syms[x].Terminal {
+					f, nullable := t.bnf.FirstOf(p.RHS[it.dot+1:])
+
+					for _, q := range t.bnf.Syms[x].Prods {
+						j := s.index[item{prod: q}]
+						changed = union(s.la[j], f) || changed
+						if nullable {
+							changed = union(s.la[j], s.la[i]) || changed
+						}
+					}
+				}
+
+				n := t.states[s.next[x]]
+				changed = union(n.la[n.index[item{prod: it.prod, dot: it.dot + 1}]], s.la[i]) || changed
+			}
+		}
+	}
+}
+
+// examples returns the shortest input that reaches every state.
+func (t *Table) examples() [][]string {
+	sh := t.bnf.Shortest()
+	ex := make([][]string, len(t.states))
+	seen := make([]bool, len(t.states))
+	seen[0] = true
+
+	for q := []int{0}; len(q) != 0; q = q[1:] {
+		s := t.states[q[0]]
+
+		syms := make([]int, 0, len(s.next))
+		for x := range s.next {
+			syms = append(syms, x)
+		}
+		sort.Ints(syms)
+
+		for _, x := range syms {
+			j := s.next[x]
+
+			if seen[j] {
+				continue
+			}
+
+			seen[j] = true
+
+			e := append([]string(nil), ex[q[0]]...)
+			if sh[x] == nil {
+				e = append(e, t.bnf.Syms[x].Name)
+			} else {
+				e = append(e, sh[x]...)
+			}
+
+			ex[j] = e
+			q = append(q, j)
+		}
+	}
+
+	return ex
+}
+
+// buildActions fills the actions of the states and records the conflicts.
+func (t *Table) buildActions() {
+	ex := t.examples()
+
+	for si, s := range t.states {
+		shifts := map[int][]string{}
+		var terms []int
+
+		for x, j := range s.next {
+			if t.bnf.Syms[x].Terminal {
+				s.actions[x] = action{kind: shiftAction, n: j}
+			}
+		}
+
+		for _, it := range s.items {
+			p := t.bnf.Prods[it.prod]
+			if it.dot < len(p.RHS) && t.bnf.Syms[p.RHS[it.dot]].Terminal {
+				x := p.RHS[it.dot]
+				shifts[x] = append(shifts[x], t.bnf.ItemString(it.prod, it.dot))
+			}
+		}
+
+		conflicts := map[int]*Conflict{}
+		var order []int
+
+		for i, it := range s.items {
+			p := t.bnf.Prods[it.prod]
+
+			if it.dot != len(p.RHS) {
+				continue
+			}
+
+			la := make([]int, 0, len(s.la[i]))
+			for x := range s.la[i] {
+				la = append(la, x)
+			}
+			sort.Ints(la)
+
+			for _, x := range la {
+				a := action{kind: reduceAction, n: it.prod}
+				if it.prod == 0 {
+					a.kind = acceptAction
+				}
+
+				cur, ok := s.actions[x]
+
+				if !ok {
+					s.actions[x] = a
+					continue
+				}
+
+				c, ok := conflicts[x]
+
+				if !ok {
+					c = &Conflict{
+						State:    si,
+						Terminal: t.bnf.Syms[x].Name,
+						Example:  append(append([]string(nil), ex[si]...), t.bnf.Syms[x].Name),
+					}
+					conflicts[x] = c
+					order = append(order, x)
+
+					if cur.kind == shiftAction {
+						c.Kind, c.Chosen = ShiftReduce, shifts[x][0]
+					} else {
+						c.Kind, c.Chosen = ReduceReduce, t.bnf.ItemString(cur.n, len(t.bnf.Prods[cur.n].RHS))
+					}
+				}
+
+				red := t.bnf.ItemString(it.prod, len(p.RHS))
+
+				if cur.kind != shiftAction && it.prod < cur.n {
+					s.actions[x] = a
+					c.Discarded = append(c.Discarded, c.Chosen)
+					c.Chosen = red
+					continue
+				}
+
+				c.Discarded = append(c.Discarded, red)
+			}
+		}
+
+		sort.Ints(order)
+		for _, x := range order {
+			t.Conflicts = append(t.Conflicts, *conflicts[x])
+		}
+
+		for x := range s.actions {
+			terms = append(terms, x)
+		}
+
+		t.bnf.SortTerminals(terms)
+		s.terms = terms
+	}
+}
+
+// shifts returns a boolean that indicates whether the terminal x is shifted or accepted after the reductions from the states ss.
+func (t *Table) shifts(ss []int, x int) bool {
+	ss = append([]int(nil), ss...)
+
+	for i := 0; i <= len(t.bnf.Prods)*len(t.states); i++ {
+		a, ok := t.states[ss[len(ss)-1]].actions[x]
+
+		if !ok {
+			return false
+		}
+
+		if a.kind != reduceAction {
+			return true
+		}
+
+		pr := t.bnf.Prods[a.n]
+		ss = ss[:len(ss)-len(pr.RHS)]
+		ss = append(ss, t.states[ss[len(ss)-1]].next[pr.LHS])
+	}
+
+	return false
+}
+
+// expected returns the labels of the terminals that are shifted or accepted from the states ss.
+func (t *Table) expected(ss []int) []string {
+	var l []string
+
+	for _, x := range t.states[ss[len(ss)-1]].terms {
+		if t.shifts(ss, x) {
+			l = append(l, t.bnf.Syms[x].Label())
+		}
+	}
+
+	return l
+}
+
+// NewTable returns the LALR(1) table of the grammar g, with s as start production, or the first one when is empty.
+// The grammar must not refer to undefined productions.
+//
+// # About the table
+//   - The EBNF expressions are replaced by helper non-terminals, named by the expression. Their nodes belong to their parent.
+//   - The Conflicts are resolved as yacc does, and they are reported with an example input.
+//
+// # Example
+//
+//	g, _ := grammar.Parse(`
+//		expr   = expr "+" term | term ;
+//		term   = term "*" factor | factor ;
+//		factor = NUMBER | "(" expr ")" ;
+//	`)
+//
+//	t, err := NewTable(g, "")
+//
+//	for _, c := range t.Conflicts {
+//		log.Println(c.String())
+//	}
+func NewTable(g *grammar.Grammar, s string) (*Table, error) {
+	if s == "" {
+		s = g.Start()
+	}
+
+	if err := grammar.Check(g, s); errors.Is(err, grammar.ErrUndefinedProduction) {
+		return nil, err
+	}
+
+	t := &Table{bnf: bnf.New(g, s)}
+
+	t.buildStates()
+	t.propagate()
+	t.buildActions()
+
+	return t, nil
+}
@@ -0,0 +1,126 @@
+package aldana
+
+import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+
+// memoKey identifies the parse of a rule at a position of the reader.
+type memoKey struct {
+	rule string
+	pos  int
+}
+
+// memoEntry is the result of a rule at a position, where end is the position after it.
+type memoEntry[Tn any] struct {
+	nd  Tn
+	err error
+	end int
+	// parsing indicates whether the rule is being parsed, so finding it again at the same position is a left recursion.
+	parsing bool
+	// recursive indicates whether the rule found itself at the same position, so its result is a seed to grow.
+	recursive bool
+	// lo and hi are the first and the last positions read by the rule, when reparsing.
+	lo, hi int
+	// seeded indicates whether the result depends on the seed of head, so it cannot be reused by a reparse.
+	seeded bool
+	head   *memoEntry[Tn]
+}
+
+// forget removes the results added to the memo after the i-th one.
+func (p *parseRun[Tt, Tn]) forget(i int) {
+	for _, k := range p.memoLog[i:] {
+		delete(p.memo, k)
+	}
+
+	p.memoLog = p.memoLog[:i]
+}
+
+// memoized parses the rule n once per position of the reader.
+//
+// # About the left recursion
+//   - The rule that is found again at the same position while it is being parsed results in its seed, which first is an
+//     ErrLeftRecursion. So the recursive alternatives fail and the other ones are parsed.
+//   - Then the rule is parsed again with the last result as seed, until it fails or it does not consume more tokens.
+//   - The results found while growing the seed are forgotten before each parse, because they could depend on the seed.
+//     So the indirect left recursions grow too.
+func (p *parseRun[Tt, Tn]) memoized(n string, m int, r parser.Reader[Tt]) (Tn, error) {
+	k := memoKey{rule: n, pos: r.Mark()}
+
+	if e, ok := p.memo[k]; ok {
+		if e.parsing {
+			e.recursive = true
+		}
+
+		if p.look != nil {
+			p.dependOn(e)
+		}
+
+		r.Reset(e.end)
+
+		return e.nd, e.err
+	}
+
+	e := &memoEntry[Tn]{err: newParseError(ErrLeftRecursion, append(p.stack, n), r), end: k.pos, parsing: true}
+	p.memo[k] = e
+	p.memoLog = append(p.memoLog, k)
+	i := len(p.memoLog)
+
+	var lo, hi int
+	if p.look != nil {
+		lo, hi = p.look.lo, p.look.hi
+		p.look.lo, p.look.hi = k.pos, k.pos
+		p.memoStack = append(p.memoStack, e)
+	}
+
+	nd, err := p.evalRule(n, m, r)
+
+	for e.recursive && err == nil && (e.err != nil || r.Mark() > e.end) {
+		e.nd, e.err, e.end = nd, nil, r.Mark()
+
+		p.forget(i)
+		r.Reset(k.pos)
+
+		nd, err = p.evalRule(n, m, r)
+	}
+
+	e.parsing = false
+
+	if !e.recursive || e.err != nil {
+		e.nd, e.err, e.end = nd, err, r.Mark()
+	}
+
+	if p.look != nil {
+		e.lo, e.hi = p.look.lo, p.look.hi
+		p.look.lo, p.look.hi = lo, hi
+		p.look.touch(e.lo)
+		p.look.touch(e.hi)
+		p.memoStack = p.memoStack[:len(p.memoStack)-1]
+	}
+
+	r.Reset(e.end)
+
+	return e.nd, e.err
+}
+
+// dependOn records that the rules in progress read the tokens of the result e. When e is a seed, or depends on one, the
+// rules in progress after the one of the seed depend on it too.
+func (p *parseRun[Tt, Tn]) dependOn(e *memoEntry[Tn]) {
+	h := e.head
+
+	if e.parsing {
+		h = e
+	} else {
+		p.look.touch(e.lo)
+		p.look.touch(e.hi)
+	}
+
+	if h == nil {
+		return
+	}
+
+	for i := len(p.memoStack) - 1; i >= 0 && p.memoStack[i] != h; i-- {
+		s := p.memoStack[i]
+		s.seeded = true
+		if s.head == nil {
+			s.head = h
+		}
+	}
+}
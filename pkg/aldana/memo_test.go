@@ -0,0 +1,142 @@
+package aldana
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func binaryNode(nds []*node) *node {
+	return &node{Type: "binary", Value: nds[1].Value, Children: []*node{nds[0], nds[2]}}
+}
+
+func nodeString(n *node) string {
+	if n.Type == "leaf" {
+		return n.Value
+	}
+
+	s := make([]string, len(n.Children))
+	for i, c := range n.Children {
+		s[i] = nodeString(c)
+	}
+
+	return "(" + s[0] + " " + n.Value + " " + strings.Join(s[1:], " ") + ")"
+}
+
+func setUpMemoParser(rules map[string]NodeRule[string, *node], memoize bool) parser.Parser[string, *node] {
+	return NewParser(&ParserOptions[string, *node]{
+		ParseRules: rules,
+		Root:       "expr",
+		Memoize:    memoize,
+	})
+}
+
+/*
+Given: a memoized parser with left recursive rules.
+When: parses acceptable tokens.
+Then: returns the node grouped by the left recursion and no error.
+*/
+func TestMemoize_with_left_recursion(t *testing.T) {
+	num := Token(isDigit, "digit", leafNode)
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+
+	cases := map[string]struct {
+		rules  map[string]NodeRule[string, *node]
+		tokens []string
+		expect string
+	}{
+		"direct": {
+			rules: map[string]NodeRule[string, *node]{
+				"expr": Alt(Seq(binaryNode, Rule[string, *node]("expr"), sym("-"), Rule[string, *node]("term")), Rule[string, *node]("term")),
+				"term": Alt(Seq(binaryNode, Rule[string, *node]("term"), sym("*"), num), num),
+			},
+			tokens: []string{"1", "-", "2", "*", "3", "*", "4", "-", "5"},
+			expect: "((1 - ((2 * 3) * 4)) - 5)",
+		},
+		"indirect": {
+			rules: map[string]NodeRule[string, *node]{
+				"expr": Alt(Rule[string, *node]("sub"), num),
+				"sub":  Seq(binaryNode, Rule[string, *node]("expr"), sym("-"), num),
+			},
+			tokens: []string{"1", "-", "2", "-", "3"},
+			expect: "((1 - 2) - 3)",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs := setUpMemoParser(c.rules, true)
+
+			// act
+			nd, err := prs.Parse(NewReader(c.tokens))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, nodeString(nd))
+		})
+	}
+}
+
+/*
+Given: a parser with a rule that is found again by the backtracking alternatives.
+When: parses acceptable tokens with and without Memoize.
+Then: the memoized parser parses the rule once per position.
+*/
+func TestMemoize_with_backtracking(t *testing.T) {
+	for name, c := range map[string]struct {
+		memoize bool
+		calls   int
+	}{
+		"memoized":     {true, 1},
+		"not memoized": {false, 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			calls := 0
+			num := Token(isDigit, "digit", leafNode)
+
+			prs := setUpMemoParser(map[string]NodeRule[string, *node]{
+				"expr": Alt(
+					Seq(binaryNode, Rule[string, *node]("atom"), Token(isToken("+"), "'+'", leafNode), num),
+					Seq(binaryNode, Rule[string, *node]("atom"), Token(isToken("-"), "'-'", leafNode), num),
+				),
+				"atom": func(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+					calls++
+					return num(r, f)
+				},
+			}, c.memoize)
+
+			// act
+			nd, err := prs.Parse(NewReader([]string{"1", "-", "2"}))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, "(1 - 2)", nodeString(nd))
+			assert.Equal(t, c.calls, calls)
+		})
+	}
+}
+
+/*
+Given: a memoized parser with a left recursive rule.
+When: parses non acceptable tokens.
+Then: returns the syntax error of the non recursive alternative.
+*/
+func TestMemoize_with_non_acceptable_tokens(t *testing.T) {
+	// arrange
+	prs := setUpMemoParser(map[string]NodeRule[string, *node]{
+		"expr": Alt(Seq(binaryNode, Rule[string, *node]("expr"), Token(isToken("-"), "'-'", leafNode), Token(isDigit, "digit", leafNode)), Token(isDigit, "digit", leafNode)),
+	}, true)
+
+	// act
+	_, err := prs.Parse(NewReader([]string{"-", "1"}))
+
+	// assert
+	assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+	assert.ErrorContains(t, err, "expected digit but found -")
+}
@@ -0,0 +1,104 @@
+package aldana
+
+import (
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+)
+
+// OperatorToken is a function that returns the token for the operator op, read at the position p.
+type OperatorToken[T any] func(op string, p lexer.Position) T
+
+// operatorNode is a node of the trie of operators.
+type operatorNode[T any] struct {
+	next map[byte]*operatorNode[T]
+	op   string
+	tk   OperatorToken[T]
+}
+
+// NewOperatorLexicalRule returns a LexicalRule that reads the longest operator of ops found at the current char.
+// Where ops maps every operator to the constructor of its token. When no operator is found, the next LexicalRule is tried.
+//
+// # Example
+//
+//	func opToken(t TokenType) OperatorToken[*Token] {
+//		return func(op string, p lexer.Position) *Token {
+//			return &Token{Type: t, Value: []byte(op), Line: p.Line, Column: p.Column}
+//		}
+//	}
+//
+//	rules := []LexicalRule[*Token]{
+//		NewOperatorLexicalRule(map[string]OperatorToken[*Token]{
+//			"=":    opToken(Assign),
+//			"==":   opToken(Eq),
+//			"===":  opToken(StrictEq),
+//			"=>":   opToken(Arrow),
+//			">>>=": opToken(UnsignedShiftAssign),
+//		}),
+//	}
+func NewOperatorLexicalRule[T any](ops map[string]OperatorToken[T]) LexicalRule[T] {
+	var first [256]bool
+
+	root := &operatorNode[T]{next: map[byte]*operatorNode[T]{}}
+
+	for op, tk := range ops {
+		if op == "" {
+			continue
+		}
+
+		first[op[0]] = true
+		n := root
+
+		for i := 0; i < len(op); i++ {
+			nx, ok := n.next[op[i]]
+			if !ok {
+				nx = &operatorNode[T]{next: map[byte]*operatorNode[T]{}}
+				n.next[op[i]] = nx
+			}
+			n = nx
+		}
+
+		n.op, n.tk = op, tk
+	}
+
+	r := ranges.ByteRange(func(b byte) bool {
+		return first[b]
+	})
+
+	f := func(c lexer.Cursor, _ ranges.ByteRange) (T, bool, error) {
+		pk, ok := c.(lexer.Peeker)
+		if !ok {
+			return *new(T), false, lexer.ErrCursorCannotPeek
+		}
+
+		var m *operatorNode[T]
+
+		n := root
+
+		for i := 0; ; i++ {
+			b, ok := pk.Peek(i)
+			if !ok {
+				break
+			}
+			if n, ok = n.next[b]; !ok {
+				break
+			}
+			if n.tk != nil {
+				m = n
+			}
+		}
+
+		if m == nil {
+			return *new(T), false, nil
+		}
+
+		t := m.tk(m.op, lexer.PositionOf(c))
+
+		for i := 0; i < len(m.op); i++ {
+			c.Next()
+		}
+
+		return t, true, nil
+	}
+
+	return NewFallibleLexicalRule(r, f)
+}
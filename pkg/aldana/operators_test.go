@@ -0,0 +1,68 @@
+package aldana
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockOperatorToken(op string, p lexer.Position) *token {
+	return &token{Type: "op", Value: []byte(op)}
+}
+
+/*
+Given: an operator lex-rule with operators that share their prefixes.
+When: tokenizes the chars.
+Then: returns the longest operator at every position.
+*/
+func TestLexer_Tokenize_with_operator_rule(t *testing.T) {
+	t.Run("longest match", func(t *testing.T) {
+		// arrange
+		lex := NewLexer(&LexerOptions[*token]{
+			Ignore: IgnoreWhiteSpaces(),
+			LexRules: []LexicalRule[*token]{
+				NewOperatorLexicalRule(map[string]OperatorToken[*token]{
+					"=":    mockOperatorToken,
+					"==":   mockOperatorToken,
+					"===":  mockOperatorToken,
+					"=>":   mockOperatorToken,
+					">":    mockOperatorToken,
+					">>":   mockOperatorToken,
+					">>>=": mockOperatorToken,
+				}),
+				mockLexerRule(),
+			},
+		})
+
+		// act
+		tks, err := lex.Tokenize(mockCursor([]byte("1 = == ===== => >>>= >>> >>=1")))
+
+		// assert
+		assert.NoError(t, err)
+
+		var ops []string
+		for _, tk := range tks {
+			ops = append(ops, string(tk.Value))
+		}
+		assert.Equal(t, []string{"1", "=", "==", "===", "==", "=>", ">>>=", ">>", ">", ">>", "=", "1"}, ops)
+	})
+
+	t.Run("no operator", func(t *testing.T) {
+		// arrange
+		lex := NewLexer(&LexerOptions[*token]{
+			LexRules: []LexicalRule[*token]{
+				NewOperatorLexicalRule(map[string]OperatorToken[*token]{
+					"!=": mockOperatorToken,
+				}),
+			},
+		})
+
+		// act
+		tks, err := lex.Tokenize(mockCursor([]byte("!=!")))
+
+		// assert
+		assert.ErrorIs(t, err, lexer.ErrUnexpectedChar)
+		assert.Nil(t, tks)
+	})
+}
@@ -0,0 +1,252 @@
+package aldana
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+)
+
+// ChunkSplitter returns the offsets of b where a chunk can safely start, in increasing order.
+// Where size is the desired length of the chunks.
+type ChunkSplitter func(b []byte, size int) []int
+
+// ParallelLexerOptions contains the options for configure the parallel implementation of the Lexer.
+type ParallelLexerOptions[T any] struct {
+	// Lexer are the options of the lexer that tokenizes every chunk.
+	Lexer *LexerOptions[T]
+	// Split returns the offsets where the input is split into chunks. When is nil, the input is tokenized sequentially,
+	// because only the lexer knows where a token can start.
+	Split ChunkSplitter
+	// ChunkSize is the desired length of the chunks. By default, is 1 MiB.
+	ChunkSize int
+	// Workers is the number of chunks tokenized at the same time. By default, is the number of CPUs.
+	Workers int
+	// Lines returns the number of lines of a chunk. By default, counts the 0x0A (new-line) bytes.
+	Lines func(b []byte) int
+}
+
+// parallelLexer implements lexer.Lexer
+type parallelLexer[T any] struct {
+	ops *ParallelLexerOptions[T]
+	lex lexer.Lexer[T]
+}
+
+func (l *parallelLexer[T]) Tokenize(c lexer.Cursor) ([]T, error) {
+	dc, ok := c.(*defaultCursor)
+
+	if !ok || dc.column != 0 || l.ops.Split == nil {
+		return l.lex.Tokenize(c)
+	}
+
+	b := dc.content
+	sz := l.ops.ChunkSize
+
+	if sz <= 0 {
+		sz = 1 << 20
+	}
+
+	st := []int{0}
+
+	for _, o := range l.ops.Split(b, sz) {
+		if o > st[len(st)-1] && o < len(b) {
+			st = append(st, o)
+		}
+	}
+
+	if len(st) == 1 {
+		return l.lex.Tokenize(c)
+	}
+
+	lns := l.ops.Lines
+
+	if lns == nil {
+		lns = func(b []byte) int {
+			return bytes.Count(b, []byte{0x0A})
+		}
+	}
+
+	ends := make([]int, len(st))
+	lines := make([]int, len(st))
+
+	for i := range st {
+		ends[i] = len(b)
+		if i+1 < len(st) {
+			ends[i] = st[i+1]
+		}
+		if i > 0 {
+			lines[i] = lines[i-1] + lns(b[st[i-1]:ends[i-1]])
+		}
+	}
+
+	wk := l.ops.Workers
+
+	if wk <= 0 {
+		wk = runtime.NumCPU()
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = len(st)
+		idx    = make(chan int)
+		tks    = make([][]T, len(st))
+		errs   = make([]error, len(st))
+	)
+
+	for w := 0; w < wk; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				mu.Lock()
+				skip := i > failed
+				mu.Unlock()
+
+				if skip {
+					continue
+				}
+
+				tks[i], errs[i] = l.lex.Tokenize(&defaultCursor{
+					content: b[:ends[i]],
+					length:  ends[i],
+					column:  st[i],
+					line:    lines[i],
+					hasChar: st[i] < ends[i],
+				})
+
+				if errs[i] != nil {
+					mu.Lock()
+					if i < failed {
+						failed = i
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range st {
+		idx <- i
+	}
+
+	close(idx)
+	wg.Wait()
+
+	if failed < len(st) {
+		return nil, errs[failed]
+	}
+
+	n := 0
+	for _, t := range tks {
+		n += len(t)
+	}
+
+	r := make([]T, 0, n)
+	for _, t := range tks {
+		r = append(r, t...)
+	}
+
+	return r, nil
+}
+
+// NewParallelLexer returns an implementation of lexer.Lexer that tokenizes large inputs in parallel.
+//
+// # About the implementation
+//   - The input is split into chunks at the offsets given by Split, which must not be in the middle of a token.
+//   - Every chunk is tokenized by the default implementation of the Lexer, with the columns and lines of the whole input.
+//   - The tokens are joined in the order of the chunks. When many chunks fail, the error of the first one is returned.
+//   - When the cursor was not created by NewCursor, or was already advanced, or the Split is nil, the input is tokenized
+//     sequentially.
+//   - The calls to the Observer of the Lexer options are serialized, so it does not need to be safe for concurrent use.
+//     But the events of the chunks are interleaved, and the chunks after a failed one can report their events too.
+//
+// # Example
+//
+//	l := NewParallelLexer(&ParallelLexerOptions[*Token]{
+//		Lexer: &LexerOptions[*Token]{
+//			Ignore:   IgnoreWhiteSpaces(),
+//			LexRules: []LexicalRule[*Token]{NewLexicalRule(ranges.ByteBounded(0x30, 0x39), lexNumbs)},
+//		},
+//		Split: SplitLines(ranges.ByteSingle('"')),
+//	})
+//
+//	b, _ := os.ReadFile("records.txt")
+//	tks, err := l.Tokenize(NewCursor(b))
+func NewParallelLexer[T any](ops *ParallelLexerOptions[T]) lexer.Lexer[T] {
+	lo := ops.Lexer
+
+	if lo.Observer != nil {
+		c := *lo
+		c.Observer = &syncLexerObserver[T]{obs: lo.Observer}
+		lo = &c
+	}
+
+	return &parallelLexer[T]{
+		ops: ops,
+		lex: NewLexer(lo),
+	}
+}
+
+// syncLexerObserver is a LexerObserver that serializes the calls to obs, which are made by the chunks' goroutines.
+type syncLexerObserver[T any] struct {
+	mu  sync.Mutex
+	obs LexerObserver[T]
+}
+
+func (o *syncLexerObserver[T]) RuleTried(i int, b byte, p lexer.Position, ok bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.obs.RuleTried(i, b, p, ok)
+}
+
+func (o *syncLexerObserver[T]) TokenEmitted(i int, t T, s lexer.Position, e lexer.Position) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.obs.TokenEmitted(i, t, s, e)
+}
+
+func (o *syncLexerObserver[T]) BytesOmitted(s lexer.Position, e lexer.Position) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.obs.BytesOmitted(s, e)
+}
+
+func (o *syncLexerObserver[T]) Error(err error, p lexer.Position) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.obs.Error(err, p)
+}
+
+// SplitLines returns a ChunkSplitter that splits just after the 0x0A (new-line) bytes.
+// The new-lines between two equal bytes of q (quotes) are ignored, such as the bytes escaped by 0x5C (back-slash) inside of them.
+func SplitLines(q ranges.ByteRange) ChunkSplitter {
+	return func(b []byte, size int) []int {
+		var (
+			off []int
+			in  bool
+			qt  byte
+		)
+
+		nx := size
+
+		for i := 0; i < len(b); i++ {
+			switch ch := b[i]; {
+			case in && ch == 0x5C:
+				i++
+			case in && ch == qt:
+				in = false
+			case in:
+			case q(ch):
+				in, qt = true, ch
+			case ch == 0x0A && i+1 >= nx:
+				off = append(off, i+1)
+				nx = i + 1 + size
+			}
+		}
+
+		return off
+	}
+}
@@ -0,0 +1,249 @@
+package aldana
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+	"github.com/stretchr/testify/assert"
+)
+
+type positionedToken struct {
+	Value  string
+	Column int
+	Line   int
+}
+
+func mockRecordsLexerOptions() *LexerOptions[*positionedToken] {
+	lex := func(c lexer.Cursor, r ranges.ByteRange) *positionedToken {
+		cl, ln := c.GetPosition()
+		t := &positionedToken{Column: cl, Line: ln}
+
+		if c.GetChar() == '"' {
+			t.Value += string(c.GetChar())
+			c.Next()
+			for c.HasChar() && c.GetChar() != '"' {
+				if c.GetChar() == 0x0A {
+					c.AddLine(1)
+				}
+				t.Value += string(c.GetChar())
+				c.Next()
+			}
+			t.Value += string(c.GetChar())
+			c.Next()
+			return t
+		}
+
+		for c.HasChar() && r(c.GetChar()) {
+			t.Value += string(c.GetChar())
+			c.Next()
+		}
+
+		return t
+	}
+
+	return &LexerOptions[*positionedToken]{
+		Ignore: func() (ranges.ByteRange, func(c lexer.Cursor, r ranges.ByteRange)) {
+			return ranges.ByteSet(0x20, 0x0A), func(c lexer.Cursor, r ranges.ByteRange) {
+				if c.GetChar() == 0x0A {
+					c.AddLine(1)
+				}
+				c.Next()
+			}
+		},
+		LexRules: []LexicalRule[*positionedToken]{
+			NewLexicalRule(ranges.ByteBounded(0x30, 0x39), lex),
+			NewLexicalRule(ranges.ByteSingle('"'), lex),
+		},
+	}
+}
+
+/*
+Given: a n-len input of records and a parallel lexer with small chunks.
+When: tokenizes the input.
+Then: returns the same tokens, with the same positions, as the sequential lexer.
+*/
+func TestParallelLexer_Tokenize(t *testing.T) {
+	// arrange
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString("12 345 \"a\nb\" 6\n")
+	}
+	in := []byte(sb.String())
+
+	seq := NewLexer(mockRecordsLexerOptions())
+	par := NewParallelLexer(&ParallelLexerOptions[*positionedToken]{
+		Lexer:     mockRecordsLexerOptions(),
+		Split:     SplitLines(ranges.ByteSingle('"')),
+		ChunkSize: 64,
+		Workers:   4,
+	})
+
+	// act
+	exp, expErr := seq.Tokenize(NewCursor(in))
+	tks, err := par.Tokenize(NewCursor(in))
+
+	// assert
+	assert.NoError(t, expErr)
+	assert.NoError(t, err)
+	assert.Len(t, tks, 800)
+	assert.Equal(t, exp, tks)
+}
+
+/*
+Given: a parallel lexer without Split.
+When: tokenizes the input.
+Then: returns the same tokens as the sequential lexer.
+*/
+func TestParallelLexer_Tokenize_without_split(t *testing.T) {
+	// arrange
+	in := []byte(strings.Repeat("12 345 \"a\nb\" 6\n", 20))
+	seq := NewLexer(mockRecordsLexerOptions())
+	par := NewParallelLexer(&ParallelLexerOptions[*positionedToken]{
+		Lexer:     mockRecordsLexerOptions(),
+		ChunkSize: 16,
+	})
+
+	// act
+	exp, expErr := seq.Tokenize(NewCursor(in))
+	tks, err := par.Tokenize(NewCursor(in))
+
+	// assert
+	assert.NoError(t, expErr)
+	assert.NoError(t, err)
+	assert.Equal(t, exp, tks)
+}
+
+/*
+Given: an input with many non acceptable chars in different chunks.
+When: tokenizes the input in parallel.
+Then: returns the error of the first chunk and no tokens.
+*/
+func TestParallelLexer_Tokenize_with_non_acceptable_chars(t *testing.T) {
+	// arrange
+	in := []byte(strings.Repeat("1 2 3\n", 50) + "A\n" + strings.Repeat("1 2 3\n", 50) + "B\n")
+	par := NewParallelLexer(&ParallelLexerOptions[*positionedToken]{
+		Lexer:     mockRecordsLexerOptions(),
+		Split:     SplitLines(ranges.ByteSingle('"')),
+		ChunkSize: 16,
+	})
+
+	// act
+	tks, err := par.Tokenize(NewCursor(in))
+
+	// assert
+	assert.ErrorIs(t, err, lexer.ErrUnexpectedChar)
+	assert.Nil(t, tks)
+}
+
+/*
+Given: a parallel lexer with fallible lex-rules and small chunks.
+When: tokenizes in parallel an input with omitted tokens, and with a failed one.
+Then: omits the tokens, or returns the error of the lex-rule.
+*/
+func TestParallelLexer_Tokenize_with_fallible_rules(t *testing.T) {
+	setUp := func() lexer.Lexer[*token] {
+		ops := fallibleLexerOptions()
+		ops.Ignore = func() (ranges.ByteRange, func(c lexer.Cursor, r ranges.ByteRange)) {
+			return ranges.ByteSet(0x20, 0x0A), func(c lexer.Cursor, r ranges.ByteRange) {
+				c.Next()
+			}
+		}
+		return NewParallelLexer(&ParallelLexerOptions[*token]{
+			Lexer:     ops,
+			Split:     SplitLines(ranges.ByteSingle('"')),
+			ChunkSize: 16,
+		})
+	}
+
+	t.Run("dropped token", func(t *testing.T) {
+		// act
+		tks, err := setUp().Tokenize(NewCursor([]byte(strings.Repeat("12 #34 56\n", 50))))
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, tks, 100)
+		for _, tk := range tks {
+			assert.NotNil(t, tk)
+		}
+	})
+
+	t.Run("failed token", func(t *testing.T) {
+		// act
+		tks, err := setUp().Tokenize(NewCursor([]byte(strings.Repeat("12 #34 56\n", 50) + "\"34")))
+
+		// assert
+		assert.ErrorIs(t, err, errUnterminated)
+		assert.Nil(t, tks)
+	})
+}
+
+/*
+Given: a parallel lexer with an observer that is not safe for concurrent use, and an input where two chunks fail.
+When: tokenizes the input in parallel.
+Then: returns the error of the earliest chunk, and the observer receives its error.
+*/
+func TestParallelLexer_Tokenize_with_observer(t *testing.T) {
+	// arrange
+	in := []byte(strings.Repeat("1 2 3\n", 50) + "A\n" + strings.Repeat("1 2 3\n", 50) + "B\n")
+	obs := &recordingPositionObserver{}
+	ops := mockRecordsLexerOptions()
+	ops.Observer = obs
+	par := NewParallelLexer(&ParallelLexerOptions[*positionedToken]{
+		Lexer:     ops,
+		Split:     SplitLines(ranges.ByteSingle('"')),
+		ChunkSize: 16,
+		Workers:   4,
+	})
+
+	// act
+	tks, err := par.Tokenize(NewCursor(in))
+
+	// assert
+	var lErr *lexer.Error
+	assert.ErrorAs(t, err, &lErr)
+	assert.ErrorIs(t, err, lexer.ErrUnexpectedChar)
+	assert.Equal(t, 301, lErr.Position.Column)
+	assert.Nil(t, tks)
+	assert.Contains(t, obs.errors, lErr.Position)
+	assert.NotZero(t, obs.events)
+}
+
+// recordingPositionObserver records the positions of the errors, and counts the other events.
+type recordingPositionObserver struct {
+	events int
+	errors []lexer.Position
+}
+
+func (o *recordingPositionObserver) RuleTried(int, byte, lexer.Position, bool) {
+	o.events++
+}
+
+func (o *recordingPositionObserver) TokenEmitted(int, *positionedToken, lexer.Position, lexer.Position) {
+	o.events++
+}
+
+func (o *recordingPositionObserver) BytesOmitted(lexer.Position, lexer.Position) {
+	o.events++
+}
+
+func (o *recordingPositionObserver) Error(_ error, p lexer.Position) {
+	o.errors = append(o.errors, p)
+}
+
+/*
+Given: an input with new-lines between quotes.
+When: splits the input by lines.
+Then: returns only the offsets after the new-lines out of the quotes.
+*/
+func TestSplitLines(t *testing.T) {
+	// arrange
+	sp := SplitLines(ranges.ByteSingle('"'))
+
+	// act
+	off := sp([]byte("1\n\"2\n\\\"\n3\"\n4\n"), 1)
+
+	// assert
+	assert.Equal(t, []int{2, 11, 13}, off)
+}
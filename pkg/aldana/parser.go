@@ -1,6 +1,10 @@
 package aldana
 
-import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+import (
+	"io"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
 
 type ParseRuleFinder[Tt any, Tn any] func(n string, r parser.Reader[Tt]) (Tn, error)
 
@@ -13,51 +17,304 @@ type ParserOptions[Tt any, Tn any] struct {
 	ParseRules map[string]NodeRule[Tt, Tn]
 	// Root is the ParserRule's as root Parser-Rule.
 	Root string
+	// Recovery makes the parser continue after the failures of the rules. When is nil, the parser stops at the first failure.
+	Recovery *RecoveryOptions[Tt, Tn]
+	// Memoize makes the parser remember the result of every rule at every position, so the backtracking rules are not
+	// parsed again, and allows the left recursive rules.
+	Memoize bool
+	// Handles are the rules referred by RuleHandle, which are added to the ParseRules.
+	Handles []*RuleHandle[Tt, Tn]
+	// Strict makes the parser fail when the Validate of the options returns an error, other than ErrPanickedParserRule.
+	Strict bool
+	// Warnings receives the errors of Validate, one per line, when the options are not Strict. Otherwise, it receives
+	// only the ErrPanickedParserRule.
+	Warnings io.Writer
+	// DryRun are tokens parsed by Validate for find the references between the rules.
+	DryRun [][]Tt
+	// Tracer records the calls to the rules of every parse. When is nil, the calls are not recorded. Use NewParseTracerFunc
+	// for get the calls of the concurrent parses.
+	Tracer *ParseTracer
 }
 
 // defaultParser implements parser.Parser.
 type defaultParser[Tt any, Tn any] struct {
 	// ops are the parser's options.
 	ops *ParserOptions[Tt, Tn]
+	// invalid is the error of the validation of the options, when they are Strict.
+	invalid error
+	// bound are the rules that take the place of the ParseRules with the same name, and receive the value of the parse.
+	bound map[string]boundRule[Tt, Tn]
 }
 
+// boundRule is a NodeRule that receives the value of the parse, such as a ContextNodeRule does with its Context.
+type boundRule[Tt any, Tn any] func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn], v any) (Tn, error)
+
 func (p *defaultParser[Tt, Tn]) Parse(r parser.Reader[Tt]) (Tn, error) {
-	pr, ok := p.ops.ParseRules[p.ops.Root]
+	return p.parseRoot(r, nil)
+}
+
+// parseRoot parses the tokens of the reader r from the Root, where v is the value given to the bound rules.
+func (p *defaultParser[Tt, Tn]) parseRoot(r parser.Reader[Tt], v any) (Tn, error) {
+	if p.invalid != nil {
+		return *new(Tn), p.invalid
+	}
+
+	rules := p.ops.rules()
 
-	if !ok {
+	if _, ok := rules[p.ops.Root]; !ok && p.bound[p.ops.Root] == nil {
 		return *new(Tn), ErrNotFoundRootParserRule
 	}
 
 	r.Next()
 
-	if !r.HasTokens() {
+	if !atToken(r) {
 		return *new(Tn), ErrNoTokenToParser
 	}
 
-	nd, err := pr(r, p.findRule)
+	return p.parse(p.ops.Root, rules, v, r, func() bool {
+		return atToken(r)
+	})
+}
 
-	if err != nil {
-		return nd, err
+func (p *defaultParser[Tt, Tn]) ParseRule(n string, r parser.Reader[Tt]) (Tn, error) {
+	if p.invalid != nil {
+		return *new(Tn), p.invalid
 	}
 
-	if r.HasTokens() {
-		return nd, parser.ErrUnhandledToken
+	start(r)
+
+	if !atToken(r) {
+		return *new(Tn), ErrNoTokenToParser
 	}
 
-	return nd, nil
+	return p.parse(n, p.ops.rules(), nil, r, func() bool {
+		return atToken(r)
+	})
+}
+
+func (p *defaultParser[Tt, Tn]) ParsePrefix(n string, r parser.Reader[Tt]) (Tn, int, error) {
+	if p.invalid != nil {
+		return *new(Tn), 0, p.invalid
+	}
+
+	start(r)
+
+	if !atToken(r) {
+		return *new(Tn), r.Position(), ErrNoTokenToParser
+	}
+
+	nd, err := p.parse(n, p.ops.rules(), nil, r, func() bool {
+		return false
+	})
+
+	return nd, r.Position(), err
+}
+
+// atToken indicates whether the reader r is at a token, which is the remaining-token check of the entry points.
+func atToken[Tt any](r parser.Reader[Tt]) bool {
+	_, ok := r.Peek(0)
+	return ok
+}
+
+// start moves the reader to the first token, unless it is already at a token.
+func start[Tt any](r parser.Reader[Tt]) {
+	if r.Position() < 0 {
+		r.Next()
+	}
+}
+
+// parse parses the rule n from the current token of the reader, where remains indicates whether tokens remain after it,
+// and v is the value given to the bound rules.
+func (p *defaultParser[Tt, Tn]) parse(n string, rules map[string]NodeRule[Tt, Tn], v any, r parser.Reader[Tt], remains func() bool) (Tn, error) {
+	run := &parseRun[Tt, Tn]{ops: p.ops, rules: rules, bound: p.bound, value: v}
+
+	if p.ops.Memoize {
+		run.memo = map[memoKey]*memoEntry[Tn]{}
+	}
+
+	return p.parseWith(run, n, r, remains)
+}
+
+// parseWith parses the rule n as parse does, with the state run.
+func (p *defaultParser[Tt, Tn]) parseWith(run *parseRun[Tt, Tn], n string, r parser.Reader[Tt], remains func() bool) (Tn, error) {
+	nd, err := run.findRule(n, r)
+
+	if p.ops.Tracer != nil {
+		p.ops.Tracer.set(run.calls)
+	}
+
+	if err == nil && remains() {
+		err = newParseError(parser.ErrUnhandledToken, nil, r)
+	}
+
+	if len(run.diags) != 0 {
+		if err != nil {
+			return nd, append(run.diags, err)
+		}
+		return nd, run.diags
+	}
+
+	return nd, err
+}
+
+// parseRun is the state of a single call to Parse.
+type parseRun[Tt any, Tn any] struct {
+	ops *ParserOptions[Tt, Tn]
+	// rules are the rules of the options by name.
+	rules map[string]NodeRule[Tt, Tn]
+	// bound are the rules of the parser that receive the value of the parse.
+	bound map[string]boundRule[Tt, Tn]
+	value any
+	// refs are the references between the rules, recorded when is not nil.
+	refs references
+	// stack are the names of the rules that are being parsed.
+	stack []string
+	// diags are the errors from which the rules recovered, and synced is the position where the last recovery ended.
+	diags  Diagnostics
+	synced int
+	// memo are the results of the rules by position, when the Memoize option is set.
+	memo map[memoKey]*memoEntry[Tn]
+	// memoLog are the keys of the memo in the order they were added.
+	memoLog []memoKey
+	// look records the tokens read by the rules of the memo, and memoStack are the entries in progress, when reparsing.
+	look      *lookReader[Tt]
+	memoStack []*memoEntry[Tn]
+	// calls are the root calls to the rules, and tracing are the calls in progress, when the Tracer is set.
+	calls   []*RuleCall
+	tracing []*RuleCall
+	// trying is the number of alternatives in progress, whose rules do not recover.
+	trying int
 }
 
-func (p *defaultParser[Tt, Tn]) findRule(n string, r parser.Reader[Tt]) (Tn, error) {
-	pr, ok := p.ops.ParseRules[n]
+func (p *parseRun[Tt, Tn]) findRule(n string, r parser.Reader[Tt]) (Tn, error) {
+	if p.refs != nil && len(p.stack) != 0 {
+		p.refs.add(p.stack[len(p.stack)-1], n)
+	}
+
+	m := -1
+
+	if tr, ok := r.(*tryReader[Tt]); ok {
+		r, m = tr.Reader, tr.from
+
+		if m < 0 {
+			p.trying++
+			defer func() {
+				p.trying--
+			}()
+		}
+	}
+
+	if p.ops.Tracer != nil {
+		return p.traceRule(n, m, r)
+	}
+
+	return p.callRule(n, m, r)
+}
+
+// callRule parses the rule n, memoized when the Memoize option is set. Where m is the position until which the failures
+// are not recovered.
+func (p *parseRun[Tt, Tn]) callRule(n string, m int, r parser.Reader[Tt]) (Tn, error) {
+	if p.memo != nil {
+		return p.memoized(n, m, r)
+	}
+
+	return p.evalRule(n, m, r)
+}
+
+// traceRule parses the rule n, and records the call.
+func (p *parseRun[Tt, Tn]) traceRule(n string, m int, r parser.Reader[Tt]) (Tn, error) {
+	c := &RuleCall{Rule: n, Start: r.Position()}
+
+	if len(p.tracing) == 0 {
+		p.calls = append(p.calls, c)
+	} else {
+		pc := p.tracing[len(p.tracing)-1]
+		pc.Calls = append(pc.Calls, c)
+	}
+
+	p.tracing = append(p.tracing, c)
+	nd, err := p.callRule(n, m, r)
+	p.tracing = p.tracing[:len(p.tracing)-1]
+
+	c.End, c.Err = r.Position(), err
+
+	if err == nil {
+		c.Node = nd
+	}
+
+	return nd, err
+}
+
+// evalRule parses the rule n, which recovers from the failures after the position m.
+func (p *parseRun[Tt, Tn]) evalRule(n string, m int, r parser.Reader[Tt]) (Tn, error) {
+	p.stack = append(p.stack, n)
+	defer func() {
+		p.stack = p.stack[:len(p.stack)-1]
+	}()
+
+	br, isBound := p.bound[n]
+	pr, ok := p.rules[n]
+
+	if !ok && !isBound {
+		return *new(Tn), newParseError(ErrNotFundParserRule, p.stack, r)
+	}
+
+	recovers := false
 
-	if !ok {
-		return *new(Tn), ErrNotFundParserRule
+	if p.ops.Recovery != nil && p.trying == 0 {
+		_, recovers = r.Peek(0)
 	}
 
-	return pr(r, p.findRule)
+	var (
+		nd  Tn
+		err error
+	)
+
+	if isBound {
+		nd, err = br(r, p.findRule, p.value)
+	} else {
+		nd, err = pr(r, p.findRule)
+	}
+
+	if err != nil {
+		err = newParseError(err, p.stack, r)
+
+		if !recovers || !committed(m, err, r) {
+			return nd, err
+		}
+
+		if en, ok := p.recover(n, err, r); ok {
+			return en, nil
+		}
+
+		return nd, err
+	}
+
+	return nd, nil
 }
 
-// NewParser returns the default implementation of parser.Parser.
+// NewParser returns the default implementation of parser.RuleParser, which parses from the Root.
+//
+// # About the implementation
+//   - The errors of the rules are wrapped into a ParseError, with the stack of the rules and the token where they failed.
+//   - When the Recovery is set, the rules with a SyncRule recover from their failures: the error is recorded, the tokens are
+//     skipped until the SyncRule, and the rule results in the error node. The parse returns the partial node and the Diagnostics.
+//     The failures from which the combinators backtrack are not recovered: the ones of the rules found by the alternatives of
+//     Alt, and the ones at the first token of the rules found by Many, Many1, Optional and SepBy. Neither a rule recovers when
+//     it starts at the end of the tokens. A rule that fails where the last recovery ended skips only that token, and after
+//     the MaxDiagnostics the rules fail without recovering.
+//   - When the Memoize is set, the result of a rule at a position is parsed once, as a packrat parser does. So the found
+//     nodes are shared by the rules that backtrack. The left recursive rules, directly or indirectly, are parsed by growing
+//     a seed: the recursive call fails first, and then the rule is parsed again with the previous result until it does
+//     not consume more tokens.
+//   - When the Tracer is set, it records the calls to the rules of every parse, with their positions, nodes and errors.
+//   - ParseRule and ParsePrefix parse from any rule, such as an expression for a REPL. ParsePrefix leaves the remaining tokens
+//     in the reader, so the fragments can be parsed one after another.
+//   - Every entry point fails with ErrNoTokenToParser and parser.ErrUnhandledToken by whether the reader is at a token, so
+//     the rules must move after the tokens they parse, as the combinators do.
+//   - The Handles are added to the ParseRules. The options are validated by Validate when they are Strict, and the parser
+//     fails with its error, or when the Warnings writer is set, which receives its errors. The rules that panic without
+//     tokens are only warned, because they can be valid rules that read their input unconditionally.
 //
 // # Example
 //
@@ -110,7 +367,15 @@ func (p *defaultParser[Tt, Tn]) findRule(n string, r parser.Reader[Tt]) (Tn, err
 //			"factor": NewParseRule(IsFactorToken, parseFactor)
 //		}
 //	})
-func NewParser[Tt any, Tn any](ops *ParserOptions[Tt, Tn]) parser.Parser[Tt, Tn] {
+func NewParser[Tt any, Tn any](ops *ParserOptions[Tt, Tn]) parser.RuleParser[Tt, Tn] {
+	p := newParser(ops)
+	p.invalid = ops.check()
+
+	return p
+}
+
+// newParser returns the defaultParser of the options ops, without validating them.
+func newParser[Tt any, Tn any](ops *ParserOptions[Tt, Tn]) *defaultParser[Tt, Tn] {
 	return &defaultParser[Tt, Tn]{
 		ops: ops,
 	}
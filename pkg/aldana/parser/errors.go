@@ -1,8 +1,92 @@
 package parser
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+)
 
 var (
 	ErrInvalidSyntax  = errors.New("the syntax is invalid")
 	ErrUnhandledToken = errors.New("unhandled token")
 )
+
+// SyntaxError is a ErrInvalidSyntax that describes the expected tokens at the furthest position the parser could reach.
+type SyntaxError struct {
+	// Expected are the labels of the expected tokens.
+	Expected []string
+	// Found is the description of the found token. Empty at the end of the tokens.
+	Found string
+	// Position is the index of the found token.
+	Position int
+	// Span is where the found token was read. Nil when the token does not implement lexer.Spanned.
+	Span *lexer.Span
+}
+
+func (e *SyntaxError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("expected ")
+
+	for i, l := range e.Expected {
+		switch {
+		case i == 0:
+		case i == len(e.Expected)-1:
+			b.WriteString(" or ")
+		default:
+			b.WriteString(", ")
+		}
+		b.WriteString(l)
+	}
+
+	if e.Found == "" {
+		b.WriteString(" but found the end of the tokens")
+	} else {
+		fmt.Fprintf(&b, " but found %s", e.Found)
+	}
+
+	if e.Span != nil {
+		fmt.Fprintf(&b, " at %s", e.Span.Start)
+	}
+
+	return b.String()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidSyntax
+}
+
+// NewSyntaxError returns a SyntaxError for the token t found at the position p, where the labels l were expected.
+//
+// # About the description of the token
+//   - When the token has a Text() string method, the text is quoted as '%s'.
+//   - When the token implements fmt.Stringer, its String() is used.
+//   - Otherwise, the token is formatted with %v.
+func NewSyntaxError(t any, p int, l ...string) *SyntaxError {
+	e := &SyntaxError{
+		Expected: l,
+		Position: p,
+	}
+
+	if t == nil {
+		return e
+	}
+
+	switch d := t.(type) {
+	case interface{ Text() string }:
+		e.Found = fmt.Sprintf("'%s'", d.Text())
+	case fmt.Stringer:
+		e.Found = d.String()
+	default:
+		e.Found = fmt.Sprintf("%v", t)
+	}
+
+	if s, ok := t.(lexer.Spanned); ok {
+		sp := s.GetSpan()
+		e.Span = &sp
+	}
+
+	return e
+}
@@ -13,6 +13,16 @@ type Reader[T any] interface {
 	GetToken() T
 	// Next advances the reader to next token.
 	Next()
+	// Peek returns the token n positions after the current one, and a boolean that indicates whether it exists.
+	// Where Peek(0) is the current token.
+	Peek(n int) (T, bool)
+	// Position returns the index of the current token.
+	// Where -1 is before the first token, and the number of tokens is after the last one.
+	Position() int
+	// Mark returns a checkpoint of the current position.
+	Mark() int
+	// Reset moves the reader to the checkpoint m returned by Mark.
+	Reset(m int)
 }
 
 // Parser provides an analyzer of tokens.
@@ -25,3 +35,14 @@ type Parser[Tt any, Tn any] interface {
 	// Parse returns the result node from the analyze of the tokens, or nil and an error.
 	Parse(r Reader[Tt]) (Tn, error)
 }
+
+// RuleParser is a Parser that can parse the tokens from any of its rules, so the fragments of a whole input can be parsed.
+// Where the parse starts from the current token of the reader, or from the first one when the reader was not advanced.
+type RuleParser[Tt any, Tn any] interface {
+	Parser[Tt, Tn]
+	// ParseRule returns the node of the rule n, which must analyze all the tokens.
+	ParseRule(n string, r Reader[Tt]) (Tn, error)
+	// ParsePrefix returns the node of the rule n, and the position of the first token after it. Where the remaining tokens
+	// are not analyzed.
+	ParsePrefix(n string, r Reader[Tt]) (Tn, int, error)
+}
@@ -0,0 +1,462 @@
+package aldana
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type node struct {
+	Type     string
+	Value    string
+	Children []*node
+}
+
+func isDigit(t string) bool {
+	return len(t) == 1 && t[0] >= '0' && t[0] <= '9'
+}
+
+func parseList(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+	nd := &node{Type: "list"}
+
+	for {
+		if _, ok := r.Peek(0); !ok {
+			return nd, nil
+		}
+
+		it, err := f("item", r)
+		if err != nil {
+			return nil, err
+		}
+
+		nd.Children = append(nd.Children, it)
+	}
+}
+
+func parseItem(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+	if t, ok := r.Peek(0); ok && t == "(" {
+		return f("group", r)
+	}
+
+	t, err := Expect(r, isDigit, "digit")
+	if err != nil {
+		return nil, err
+	}
+
+	return &node{Type: "num", Value: t}, nil
+}
+
+func setUpParser(rules map[string]NodeRule[string, *node]) parser.Parser[string, *node] {
+	return NewParser(&ParserOptions[string, *node]{
+		ParseRules: rules,
+		Root:       "list",
+	})
+}
+
+/*
+Given: parse rules and acceptable tokens.
+When: parses the tokens.
+Then: returns the node and no error.
+*/
+func TestParser_Parse_with_acceptable_tokens(t *testing.T) {
+	// arrange
+	prs := setUpParser(map[string]NodeRule[string, *node]{
+		"list": parseList,
+		"item": parseItem,
+	})
+
+	// act
+	nd, err := prs.Parse(NewReader([]string{"1", "2", "3"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, nd.Children, 3)
+}
+
+/*
+Given: parse rules and non acceptable tokens.
+When: parses the tokens.
+Then: returns a ParseError with the stack of rules and the failed token.
+*/
+func TestParser_Parse_with_parse_error(t *testing.T) {
+	t.Run("failed rule", func(t *testing.T) {
+		// arrange
+		prs := setUpParser(map[string]NodeRule[string, *node]{
+			"list": parseList,
+			"item": parseItem,
+		})
+
+		// act
+		_, err := prs.Parse(NewReader([]string{"1", "x", "3"}))
+
+		// assert
+		var pErr *ParseError
+		assert.ErrorAs(t, err, &pErr)
+		assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+		assert.Equal(t, []string{"list", "item"}, pErr.Stack)
+		assert.Equal(t, "x", pErr.Token)
+		assert.Equal(t, 1, pErr.Position)
+		assert.EqualError(t, err, "list > item: expected digit but found x")
+	})
+
+	t.Run("not found rule", func(t *testing.T) {
+		// arrange
+		prs := setUpParser(map[string]NodeRule[string, *node]{
+			"list": parseList,
+			"item": parseItem,
+		})
+
+		// act
+		_, err := prs.Parse(NewReader([]string{"1", "(", "3"}))
+
+		// assert
+		var pErr *ParseError
+		assert.ErrorAs(t, err, &pErr)
+		assert.ErrorIs(t, err, ErrNotFundParserRule)
+		assert.Equal(t, []string{"list", "item", "group"}, pErr.Stack)
+		assert.Equal(t, "(", pErr.Token)
+	})
+}
+
+/*
+Given: parse rules with recovery and many non acceptable tokens.
+When: parses the tokens.
+Then: returns the partial node, with error nodes, and the Diagnostics of every failure.
+*/
+func TestParser_Parse_with_recovery(t *testing.T) {
+	// arrange
+	isSemicolon := func(t string) bool {
+		return t == ";"
+	}
+	prs := NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"list": parseList,
+			"item": func(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+				nd, err := parseItem(r, f)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := Expect(r, isSemicolon, "';'"); err != nil {
+					return nil, err
+				}
+				return nd, nil
+			},
+		},
+		Root: "list",
+		Recovery: &RecoveryOptions[string, *node]{
+			Sync: map[string]SyncRule[string]{
+				"item": {Until: []TokenPredicate[string]{isSemicolon}, Consume: true},
+			},
+			ErrorNode: func(n string, err error) *node {
+				return &node{Type: "error", Value: n}
+			},
+		},
+	})
+
+	// act
+	nd, err := prs.Parse(NewReader([]string{"1", ";", "x", ";", "2", ";", "3", "4", "5", ";", "6", ";"}))
+
+	// assert
+	var diags Diagnostics
+	assert.ErrorAs(t, err, &diags)
+	assert.Len(t, diags, 2)
+	assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+
+	var ty []string
+	for _, c := range nd.Children {
+		ty = append(ty, c.Type+":"+c.Value)
+	}
+	assert.Equal(t, []string{"num:1", "error:item", "num:2", "error:item", "num:6"}, ty)
+}
+
+/*
+Given: parse rules made by combinators, which move the reader after the tokens they parse.
+When: parses a single token, all the tokens, and tokens whose only leftover is the last one, by Parse and by ParseRule.
+Then: returns the node when all the tokens are parsed, otherwise fails with ErrUnhandledToken at the last token.
+*/
+func TestParser_remaining_tokens(t *testing.T) {
+	cases := map[string]struct {
+		rule   string
+		tokens []string
+		err    error
+	}{
+		"single token":     {rule: "item", tokens: []string{"1"}},
+		"all tokens":       {rule: "pair", tokens: []string{"1", "2"}},
+		"last token":       {rule: "pair", tokens: []string{"1", "2", "3"}, err: parser.ErrUnhandledToken},
+		"last single item": {rule: "item", tokens: []string{"1", "2"}, err: parser.ErrUnhandledToken},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs := NewParser(&ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"pair": Seq(listNode, Rule[string, *node]("item"), Rule[string, *node]("item")),
+					"item": Token(isDigit, "digit", leafNode),
+				},
+				Root: c.rule,
+			})
+
+			// act
+			nd, err := prs.Parse(NewReader(c.tokens))
+			rNd, rErr := prs.ParseRule(c.rule, NewReader(c.tokens))
+
+			// assert
+			if c.err == nil {
+				assert.NoError(t, err)
+				assert.NoError(t, rErr)
+				assert.Equal(t, nd, rNd)
+				return
+			}
+
+			var pErr *ParseError
+			assert.ErrorIs(t, err, c.err)
+			assert.ErrorIs(t, rErr, c.err)
+			assert.ErrorAs(t, err, &pErr)
+			assert.Equal(t, len(c.tokens)-1, pErr.Position)
+		})
+	}
+}
+
+/*
+Given: parse rules with recovery and an empty statement.
+When: parses the tokens.
+Then: returns the error node of the empty statement, skipping only its sync token.
+*/
+func TestParser_Parse_with_recovery_of_empty_statement(t *testing.T) {
+	// arrange
+	isSemicolon := isToken(";")
+	join := func(nds []*node) *node {
+		return &node{Type: "list", Children: nds}
+	}
+	prs := NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program":   Seq(join, Rule[string, *node]("statement"), Rule[string, *node]("statement"), Rule[string, *node]("statement"), Rule[string, *node]("statement")),
+			"statement": Seq(func(nds []*node) *node { return nds[0] }, Token(isDigit, "digit", leafNode), Token(isSemicolon, "';'", leafNode)),
+		},
+		Root: "program",
+		Recovery: &RecoveryOptions[string, *node]{
+			Sync: map[string]SyncRule[string]{
+				"statement": {Until: []TokenPredicate[string]{isSemicolon}, Consume: true},
+			},
+			ErrorNode: func(n string, err error) *node {
+				return &node{Type: "error", Value: n}
+			},
+		},
+	})
+
+	// act
+	nd, err := prs.Parse(NewReader([]string{"1", ";", ";", "2", ";", "3", ";"}))
+
+	// assert
+	var diags Diagnostics
+	assert.ErrorAs(t, err, &diags)
+	assert.Len(t, diags, 1)
+
+	var ty []string
+	for _, c := range nd.Children {
+		ty = append(ty, c.Type+":"+c.Value)
+	}
+	assert.Equal(t, []string{"leaf:1", "error:statement", "leaf:2", "leaf:3"}, ty)
+}
+
+/*
+Given: parse rules with recovery, whose sync token is not consumed, and a loop of the rule in a hand-written rule.
+When: parses tokens where the rule fails at its sync token, and tokens with more failures than the MaxDiagnostics.
+Then: skips the sync token where the recovery stalled, and fails after the MaxDiagnostics.
+*/
+func TestParser_Parse_with_recovery_progress(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		max    int
+		diags  int
+		types  []string
+	}{
+		"stalled sync":    {tokens: []string{"1", ";", ";", "2", ";"}, diags: 2, types: []string{"leaf:1", "error:item", "error:item", "leaf:2"}},
+		"max diagnostics": {tokens: []string{"1", ";", "x", ";", "y", ";", "2", ";"}, max: 1, diags: 2},
+	}
+
+	isSemicolon := isToken(";")
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			prs := NewParser(&ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list": parseList,
+					"item": Seq(func(nds []*node) *node { return nds[0] }, Token(isDigit, "digit", leafNode), Token(isSemicolon, "';'", leafNode)),
+				},
+				Root: "list",
+				Recovery: &RecoveryOptions[string, *node]{
+					Sync: map[string]SyncRule[string]{
+						"item": {Until: []TokenPredicate[string]{isSemicolon}},
+					},
+					ErrorNode: func(n string, err error) *node {
+						return &node{Type: "error", Value: n}
+					},
+					MaxDiagnostics: c.max,
+				},
+			})
+
+			// act
+			nd, err := prs.Parse(NewReader(c.tokens))
+
+			// assert
+			var diags Diagnostics
+			if assert.ErrorAs(t, err, &diags) {
+				assert.Len(t, diags, c.diags)
+			}
+
+			if c.types == nil {
+				assert.Nil(t, nd)
+				return
+			}
+
+			var ty []string
+			for _, n := range nd.Children {
+				ty = append(ty, n.Type+":"+n.Value)
+			}
+			assert.Equal(t, c.types, ty)
+		})
+	}
+}
+
+/*
+Given: parse rules made by combinators, with recovery of the alternatives and of the repeated rule.
+When: parses acceptable and non acceptable tokens.
+Then: returns the node without Diagnostics for the acceptable ones, otherwise recovers only from the committed failures.
+*/
+func TestParser_Parse_with_recovery_of_combinators(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		types  []string
+		diags  int
+	}{
+		"acceptable":       {tokens: []string{"a", "=", "1", ";", "f", "(", ")", ";", "b", "=", "2", ";"}, types: []string{"assign:a", "call:f", "assign:b"}},
+		"failed statement": {tokens: []string{"a", "=", "1", ";", "b", "=", "x", ";", "c", "=", "3", ";"}, types: []string{"assign:a", "error:statement", "assign:c"}, diags: 1},
+		"first statement":  {tokens: []string{"f", "(", "1", ";", "c", "=", "3", ";"}, types: []string{"error:statement", "assign:c"}, diags: 1},
+	}
+
+	isSemicolon := isToken(";")
+	sym := func(v string) NodeRule[string, *node] {
+		return Token(isToken(v), "'"+v+"'", leafNode)
+	}
+	sync := SyncRule[string]{Until: []TokenPredicate[string]{isSemicolon}, Consume: true}
+	prs := NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program":   Many1(Rule[string, *node]("statement"), listNode),
+			"statement": Alt(Rule[string, *node]("assignment"), Rule[string, *node]("call")),
+			"assignment": Seq(func(nds []*node) *node {
+				return &node{Type: "assign", Value: nds[0].Value}
+			}, Token(isIdentifier, "identifier", leafNode), sym("="), Token(isDigit, "digit", leafNode), sym(";")),
+			"call": Seq(func(nds []*node) *node {
+				return &node{Type: "call", Value: nds[0].Value}
+			}, Token(isIdentifier, "identifier", leafNode), sym("("), sym(")"), sym(";")),
+		},
+		Root: "program",
+		Recovery: &RecoveryOptions[string, *node]{
+			Sync: map[string]SyncRule[string]{
+				"statement":  sync,
+				"assignment": sync,
+			},
+			ErrorNode: func(n string, err error) *node {
+				return &node{Type: "error", Value: n}
+			},
+		},
+	})
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			nd, err := prs.Parse(NewReader(c.tokens))
+
+			// assert
+			var diags Diagnostics
+			if c.diags == 0 {
+				assert.NoError(t, err)
+			} else if assert.ErrorAs(t, err, &diags) {
+				assert.Len(t, diags, c.diags)
+			}
+
+			var ty []string
+			for _, n := range nd.Children {
+				ty = append(ty, n.Type+":"+n.Value)
+			}
+			assert.Equal(t, c.types, ty)
+		})
+	}
+}
+
+/*
+Given: parse rules and the tokens of a fragment.
+When: parses the tokens from a rule other than the root.
+Then: returns the node of the rule, or ErrUnhandledToken when tokens remain.
+*/
+func TestParser_ParseRule(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		value  string
+		err    error
+	}{
+		"single token":     {tokens: []string{"7"}, value: "7"},
+		"remaining tokens": {tokens: []string{"7", "8"}, err: parser.ErrUnhandledToken},
+		"no tokens":        {tokens: []string{}, err: ErrNoTokenToParser},
+		"not found rule":   {tokens: []string{"("}, err: ErrNotFundParserRule},
+	}
+
+	prs := NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"list": parseList,
+			"item": parseItem,
+		},
+		Root: "list",
+	})
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			nd, err := prs.ParseRule("item", NewReader(c.tokens))
+
+			// assert
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.value, nd.Value)
+		})
+	}
+}
+
+/*
+Given: parse rules and the tokens of many fragments.
+When: parses the prefixes of the tokens one after another, from a rule other than the root.
+Then: returns the node of every fragment and the position after it, leaving the remaining tokens.
+*/
+func TestParser_ParsePrefix(t *testing.T) {
+	// arrange
+	prs := NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"list": parseList,
+			"item": parseItem,
+		},
+		Root: "list",
+	})
+	r := NewReader([]string{"1", "2", "x"})
+
+	// act
+	n1, p1, err1 := prs.ParsePrefix("item", r)
+	n2, p2, err2 := prs.ParsePrefix("item", r)
+	_, p3, err3 := prs.ParsePrefix("item", r)
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, "1", n1.Value)
+	assert.Equal(t, 1, p1)
+	assert.Equal(t, "2", n2.Value)
+	assert.Equal(t, 2, p2)
+	assert.ErrorIs(t, err3, parser.ErrInvalidSyntax)
+	assert.Equal(t, 2, p3)
+}
@@ -0,0 +1,195 @@
+package aldana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RuleCall is a call to a rule through the ParseRuleFinder, with the calls that it made.
+type RuleCall struct {
+	// Rule is the name of the rule.
+	Rule string
+	// Start and End are the positions of the reader before and after the call.
+	Start int
+	End   int
+	// Node is the node of the rule. Nil when it failed.
+	Node any
+	// Err is the error of the rule. Nil when it succeeded.
+	Err error
+	// Calls are the calls made by the rule, in order.
+	Calls []*RuleCall
+}
+
+// ruleCallJSON is the JSON representation of a RuleCall.
+type ruleCallJSON struct {
+	Rule  string          `json:"rule"`
+	Start int             `json:"start"`
+	End   int             `json:"end"`
+	Node  string          `json:"node,omitempty"`
+	Error string          `json:"error,omitempty"`
+	Calls []*ruleCallJSON `json:"calls,omitempty"`
+}
+
+func (c *RuleCall) toJSON() *ruleCallJSON {
+	j := &ruleCallJSON{Rule: c.Rule, Start: c.Start, End: c.End}
+
+	if c.Err != nil {
+		j.Error = c.Err.Error()
+	} else {
+		j.Node = fmt.Sprint(c.Node)
+	}
+
+	for _, cc := range c.Calls {
+		j.Calls = append(j.Calls, cc.toJSON())
+	}
+
+	return j
+}
+
+// ParseTracer records the calls to the rules of the last parse of the parsers that use it. The parses of many goroutines
+// replace the calls of each other, so use NewParseTracerFunc for get the calls of every parse.
+type ParseTracer struct {
+	mu    sync.Mutex
+	calls []*RuleCall
+	// f receives the calls of every parse, when is not nil.
+	f func(calls []*RuleCall)
+}
+
+// Calls returns the root calls of the last parse, of any goroutine.
+func (t *ParseTracer) Calls() []*RuleCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.calls
+}
+
+// Write writes the calls of the last parse into w, using the format f.
+//   - TraceText writes a line per call, indented by its depth: rule start-end -> node, or rule start-end error: err.
+//   - TraceJSON writes the calls as a JSON array of objects, where every object has the calls that it made.
+//   - TraceDOT writes the calls as a Graphviz digraph, where the failed calls are red.
+func (t *ParseTracer) Write(w io.Writer, f TraceFormat) error {
+	calls := t.Calls()
+
+	switch f {
+	case TraceJSON:
+		j := make([]*ruleCallJSON, len(calls))
+		for i, c := range calls {
+			j[i] = c.toJSON()
+		}
+
+		b, err := json.Marshal(j)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(append(b, '\n'))
+		return err
+	case TraceDOT:
+		var b strings.Builder
+
+		b.WriteString("digraph trace {\n\tnode [shape=box];\n")
+
+		n := 0
+		for _, c := range calls {
+			writeDOT(&b, c, &n)
+		}
+
+		b.WriteString("}\n")
+
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		var b strings.Builder
+
+		for _, c := range calls {
+			writeText(&b, c, 0)
+		}
+
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+}
+
+func writeText(b *strings.Builder, c *RuleCall, depth int) {
+	fmt.Fprintf(b, "%s%s %d-%d", strings.Repeat("  ", depth), c.Rule, c.Start, c.End)
+
+	if c.Err != nil {
+		fmt.Fprintf(b, " error: %s\n", c.Err)
+	} else {
+		fmt.Fprintf(b, " -> %v\n", c.Node)
+	}
+
+	for _, cc := range c.Calls {
+		writeText(b, cc, depth+1)
+	}
+}
+
+// writeDOT writes the node of the call c, named by the counter n, and the edges to its calls. Returns the node's name.
+func writeDOT(b *strings.Builder, c *RuleCall, n *int) string {
+	id := "n" + strconv.Itoa(*n)
+	*n++
+
+	l := fmt.Sprintf("%s %d-%d", c.Rule, c.Start, c.End)
+
+	if c.Err != nil {
+		fmt.Fprintf(b, "\t%s [label=%s, color=red];\n", id, strconv.Quote(l+"\n"+c.Err.Error()))
+	} else {
+		fmt.Fprintf(b, "\t%s [label=%s];\n", id, strconv.Quote(l))
+	}
+
+	for _, cc := range c.Calls {
+		fmt.Fprintf(b, "\t%s -> %s;\n", id, writeDOT(b, cc, n))
+	}
+
+	return id
+}
+
+// set replaces the calls of the last parse, and gives them to the f of the tracer.
+func (t *ParseTracer) set(calls []*RuleCall) {
+	t.mu.Lock()
+	t.calls = calls
+	t.mu.Unlock()
+
+	if t.f != nil {
+		t.f(calls)
+	}
+}
+
+// NewParseTracer returns a ParseTracer without calls.
+//
+// # Example
+//
+//	tr := NewParseTracer()
+//
+//	prs := NewParser(&ParserOptions[*Token, *Node]{
+//		ParseRules: rules,
+//		Root:       "program",
+//		Tracer:     tr,
+//	})
+//
+//	if _, err := prs.Parse(NewReader(tks)); err != nil {
+//		tr.Write(os.Stderr, TraceText)
+//	}
+func NewParseTracer() *ParseTracer {
+	return &ParseTracer{}
+}
+
+// NewParseTracerFunc returns a ParseTracer that calls f with the root calls of every parse, in the goroutine of the parse.
+// So the calls of the concurrent parses are not mixed.
+//
+// # Example
+//
+//	prs := NewParser(&ParserOptions[*Token, *Node]{
+//		ParseRules: rules,
+//		Root:       "program",
+//		Tracer: NewParseTracerFunc(func(calls []*RuleCall) {
+//			traces <- calls
+//		}),
+//	})
+func NewParseTracerFunc(f func(calls []*RuleCall)) *ParseTracer {
+	return &ParseTracer{f: f}
+}
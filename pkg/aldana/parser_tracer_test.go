@@ -0,0 +1,164 @@
+package aldana
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpTracedParser(tr *ParseTracer) parser.Parser[string, *node] {
+	return NewParser(&ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"list": SepBy(Rule[string, *node]("item"), Token(isToken(","), "','", leafNode), listNode),
+			"item": Alt(Rule[string, *node]("number"), Rule[string, *node]("name")),
+			"number": Map(Token(isDigit, "digit", leafNode), func(n *node) *node {
+				return &node{Type: "number", Value: n.Value}
+			}),
+			"name": Token(isIdentifier, "identifier", leafNode),
+		},
+		Root:   "list",
+		Tracer: tr,
+	})
+}
+
+/*
+Given: a parser with a tracer.
+When: parses acceptable tokens.
+Then: records every call to the rules with its positions, node and error.
+*/
+func TestParseTracer_Calls(t *testing.T) {
+	// arrange
+	tr := NewParseTracer()
+	prs := setUpTracedParser(tr)
+
+	// act
+	_, err := prs.Parse(NewReader([]string{"1", ",", "a"}))
+
+	// assert
+	assert.NoError(t, err)
+
+	calls := tr.Calls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "list", calls[0].Rule)
+	assert.Equal(t, 0, calls[0].Start)
+	assert.Equal(t, 3, calls[0].End)
+	assert.Len(t, calls[0].Calls, 2)
+
+	second := calls[0].Calls[1]
+	assert.Equal(t, 2, second.Start)
+	assert.Equal(t, 3, second.End)
+	assert.Len(t, second.Calls, 2)
+	assert.Equal(t, "number", second.Calls[0].Rule)
+	assert.ErrorIs(t, second.Calls[0].Err, parser.ErrInvalidSyntax)
+	assert.Nil(t, second.Calls[0].Node)
+	assert.Equal(t, "name", second.Calls[1].Rule)
+	assert.NoError(t, second.Calls[1].Err)
+	assert.Equal(t, "a", second.Calls[1].Node.(*node).Value)
+}
+
+/*
+Given: a parser with a tracer.
+When: parses non acceptable tokens and writes the trace in every format.
+Then: writes the calls as indented text, JSON and DOT, including the backtracked ones.
+*/
+func TestParseTracer_Write(t *testing.T) {
+	// arrange
+	tr := NewParseTracer()
+	prs := setUpTracedParser(tr)
+
+	_, pErr := prs.Parse(NewReader([]string{"1", ",", "+"}))
+
+	t.Run("text", func(t *testing.T) {
+		// act
+		var b bytes.Buffer
+		err := tr.Write(&b, TraceText)
+
+		// assert
+		assert.ErrorIs(t, pErr, parser.ErrUnhandledToken)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		assert.Len(t, lines, 6)
+		assert.True(t, strings.HasPrefix(lines[0], "list 0-1 -> "))
+		assert.True(t, strings.HasPrefix(lines[1], "  item 0-1 -> "))
+		assert.True(t, strings.HasPrefix(lines[2], "    number 0-1 -> "))
+		assert.Equal(t, "  item 2-2 error: list > item > name: expected digit or identifier but found +", lines[3])
+		assert.Equal(t, "    number 2-2 error: list > item > number: expected digit but found +", lines[4])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// act
+		var b bytes.Buffer
+		err := tr.Write(&b, TraceJSON)
+
+		// assert
+		assert.NoError(t, err)
+
+		var calls []map[string]any
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &calls))
+		assert.Len(t, calls, 1)
+		assert.Equal(t, "list", calls[0]["rule"])
+		assert.Contains(t, calls[0], "node")
+		assert.Len(t, calls[0]["calls"], 2)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		// act
+		var b bytes.Buffer
+		err := tr.Write(&b, TraceDOT)
+
+		// assert
+		assert.NoError(t, err)
+		s := b.String()
+		assert.True(t, strings.HasPrefix(s, "digraph trace {\n"))
+		assert.Contains(t, s, "\tn1 [label=\"item 0-1\"];\n")
+		assert.Contains(t, s, "\tn0 -> n1;\n")
+		assert.Equal(t, 5, strings.Count(s, " -> "))
+		assert.Equal(t, 3, strings.Count(s, "color=red"))
+	})
+}
+
+/*
+Given: a parser with a tracer of a function.
+When: parses many lists concurrently.
+Then: calls the function with the calls of every parse, without mixing them.
+*/
+func TestNewParseTracerFunc(t *testing.T) {
+	// arrange
+	var (
+		mu   sync.Mutex
+		ends []int
+		wg   sync.WaitGroup
+	)
+
+	prs := setUpTracedParser(NewParseTracerFunc(func(calls []*RuleCall) {
+		mu.Lock()
+		defer mu.Unlock()
+		ends = append(ends, calls[0].End)
+	}))
+
+	// act
+	for i := 2; i <= 9; i++ {
+		tks := []string{"1"}
+		for j := 1; j < i; j++ {
+			tks = append(tks, ",", "a")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = prs.Parse(NewReader(tks))
+		}()
+	}
+
+	wg.Wait()
+
+	// assert
+	sort.Ints(ends)
+	assert.Equal(t, []int{3, 5, 7, 9, 11, 13, 15, 17}, ends)
+}
@@ -0,0 +1,210 @@
+package aldana
+
+import (
+	"math"
+	"sync"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// Associativity is the way of how the infix operators of equal precedence are grouped.
+type Associativity int
+
+const (
+	// LeftAssociative groups the operators from the left: a - b - c is (a - b) - c.
+	LeftAssociative Associativity = iota
+	// RightAssociative groups the operators from the right: a ^ b ^ c is a ^ (b ^ c).
+	RightAssociative
+	// NonAssociative does not allow the operator next to another one of equal precedence: a == b == c fails with a
+	// parser.SyntaxError at the second operator.
+	NonAssociative
+)
+
+// UnaryNode is a function that returns the node of the unary operator op applied to x.
+type UnaryNode[Tt any, Tn any] func(op Tt, x Tn) Tn
+
+// BinaryNode is a function that returns the node of the binary operator op applied to l and r.
+type BinaryNode[Tt any, Tn any] func(op Tt, l Tn, r Tn) Tn
+
+type unaryOperator[Tt any, Tn any] struct {
+	p    TokenPredicate[Tt]
+	prec int
+	n    UnaryNode[Tt, Tn]
+}
+
+type binaryOperator[Tt any, Tn any] struct {
+	p     TokenPredicate[Tt]
+	prec  int
+	assoc Associativity
+	n     BinaryNode[Tt, Tn]
+}
+
+// OperatorTable contains the operators of the expressions parsed by NewPrattRule.
+// The operators can be added at any time, even while parsing, and the first added one has the priority on equal tokens.
+type OperatorTable[Tt any, Tn any] struct {
+	// Operand is the name of the rule that parses the operands, such as literals or parenthesized expressions.
+	Operand string
+
+	mu      sync.RWMutex
+	prefix  []unaryOperator[Tt, Tn]
+	postfix []unaryOperator[Tt, Tn]
+	infix   []binaryOperator[Tt, Tn]
+}
+
+// AddPrefix adds a prefix operator, with the precedence prec, for the tokens that satisfy p.
+// Where the greater precedence binds tighter.
+func (t *OperatorTable[Tt, Tn]) AddPrefix(p TokenPredicate[Tt], prec int, n UnaryNode[Tt, Tn]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prefix = append(t.prefix, unaryOperator[Tt, Tn]{p: p, prec: prec, n: n})
+}
+
+// AddPostfix adds a postfix operator, with the precedence prec, for the tokens that satisfy p.
+// Where the greater precedence binds tighter.
+func (t *OperatorTable[Tt, Tn]) AddPostfix(p TokenPredicate[Tt], prec int, n UnaryNode[Tt, Tn]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.postfix = append(t.postfix, unaryOperator[Tt, Tn]{p: p, prec: prec, n: n})
+}
+
+// AddInfix adds an infix operator, with the precedence prec and the associativity a, for the tokens that satisfy p.
+// Where the greater precedence binds tighter.
+func (t *OperatorTable[Tt, Tn]) AddInfix(p TokenPredicate[Tt], prec int, a Associativity, n BinaryNode[Tt, Tn]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.infix = append(t.infix, binaryOperator[Tt, Tn]{p: p, prec: prec, assoc: a, n: n})
+}
+
+func (t *OperatorTable[Tt, Tn]) findUnary(post bool, tk Tt) (unaryOperator[Tt, Tn], bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ops := t.prefix
+	if post {
+		ops = t.postfix
+	}
+
+	for _, op := range ops {
+		if op.p(tk) {
+			return op, true
+		}
+	}
+
+	return unaryOperator[Tt, Tn]{}, false
+}
+
+func (t *OperatorTable[Tt, Tn]) findInfix(tk Tt) (binaryOperator[Tt, Tn], bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, op := range t.infix {
+		if op.p(tk) {
+			return op, true
+		}
+	}
+
+	return binaryOperator[Tt, Tn]{}, false
+}
+
+// parse returns the node of the expression whose operators have a precedence greater or equal to mp.
+func (t *OperatorTable[Tt, Tn]) parse(mp int, r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	var (
+		nd   Tn
+		op   unaryOperator[Tt, Tn]
+		isOp bool
+	)
+
+	if tk, ok := r.Peek(0); ok {
+		op, isOp = t.findUnary(false, tk)
+	}
+
+	if isOp {
+		tk := r.GetToken()
+		r.Next()
+		x, err := t.parse(op.prec, r, f)
+		if err != nil {
+			return x, err
+		}
+		nd = op.n(tk, x)
+	} else {
+		x, err := f(t.Operand, r)
+		if err != nil {
+			return x, err
+		}
+		nd = x
+	}
+
+	for {
+		tk, ok := r.Peek(0)
+
+		if !ok {
+			return nd, nil
+		}
+
+		if op, isOp := t.findUnary(true, tk); isOp && op.prec >= mp {
+			r.Next()
+			nd = op.n(tk, nd)
+			continue
+		}
+
+		op, isOp := t.findInfix(tk)
+
+		if !isOp || op.prec < mp {
+			return nd, nil
+		}
+
+		r.Next()
+
+		np := op.prec + 1
+		if op.assoc == RightAssociative {
+			np = op.prec
+		}
+
+		x, err := t.parse(np, r, f)
+		if err != nil {
+			return x, err
+		}
+
+		nd = op.n(tk, nd, x)
+
+		if op.assoc != NonAssociative {
+			continue
+		}
+
+		if nx, ok := r.Peek(0); ok {
+			if nop, isOp := t.findInfix(nx); isOp && nop.prec == op.prec {
+				return nd, parser.NewSyntaxError(nx, r.Position(), "end of the non associative expression")
+			}
+		}
+	}
+}
+
+// NewOperatorTable returns an empty OperatorTable, where the operands are parsed by the rule named o.
+func NewOperatorTable[Tt any, Tn any](o string) *OperatorTable[Tt, Tn] {
+	return &OperatorTable[Tt, Tn]{
+		Operand: o,
+	}
+}
+
+// NewPrattRule returns a NodeRule that parses the expressions of the operators in the table t, by precedence climbing.
+//
+// # Example
+//
+//	ops := NewOperatorTable[*Token, *Node]("factor")
+//	ops.AddInfix(IsOperator("+"), 10, LeftAssociative, NewBinaryNode)
+//	ops.AddInfix(IsOperator("*"), 20, LeftAssociative, NewBinaryNode)
+//	ops.AddInfix(IsOperator("^"), 30, RightAssociative, NewBinaryNode)
+//	ops.AddPrefix(IsOperator("-"), 25, NewUnaryNode)
+//
+//	prs := NewParser(&ParserOptions[*Token, *Node]{
+//		ParseRules: map[string]NodeRule[*Token, *Node]{
+//			"expression": NewPrattRule(ops),
+//			"factor":     parseFactor,
+//		},
+//		Root: "expression",
+//	})
+func NewPrattRule[Tt any, Tn any](t *OperatorTable[Tt, Tn]) NodeRule[Tt, Tn] {
+	return func(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+		return t.parse(math.MinInt, r, f)
+	}
+}
@@ -0,0 +1,130 @@
+package aldana
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func isOperator(op string) TokenPredicate[string] {
+	return func(t string) bool {
+		return t == op
+	}
+}
+
+func unaryString(op string, x string) string {
+	return fmt.Sprintf("(%s%s)", op, x)
+}
+
+func postfixString(op string, x string) string {
+	return fmt.Sprintf("(%s%s)", x, op)
+}
+
+func binaryString(op string, l string, r string) string {
+	return fmt.Sprintf("(%s %s %s)", l, op, r)
+}
+
+func parseAtom(r parser.Reader[string], f ParseRuleFinder[string, string]) (string, error) {
+	if _, ok := Accept(r, isOperator("("), "'('"); ok {
+		nd, err := f("expr", r)
+		if err != nil {
+			return "", err
+		}
+		if _, err := Expect(r, isOperator(")"), "')'"); err != nil {
+			return "", err
+		}
+		return nd, nil
+	}
+	return Expect(r, isDigit, "digit")
+}
+
+func setUpPrattParser() (*OperatorTable[string, string], parser.Parser[string, string]) {
+	ops := NewOperatorTable[string, string]("atom")
+	ops.AddInfix(isOperator("=="), 5, NonAssociative, binaryString)
+	ops.AddInfix(isOperator("+"), 10, LeftAssociative, binaryString)
+	ops.AddInfix(isOperator("-"), 10, LeftAssociative, binaryString)
+	ops.AddInfix(isOperator("*"), 20, LeftAssociative, binaryString)
+	ops.AddInfix(isOperator("^"), 30, RightAssociative, binaryString)
+	ops.AddPrefix(isOperator("-"), 25, unaryString)
+	ops.AddPostfix(isOperator("!"), 40, postfixString)
+
+	return ops, NewParser(&ParserOptions[string, string]{
+		ParseRules: map[string]NodeRule[string, string]{
+			"expr": NewPrattRule(ops),
+			"atom": parseAtom,
+		},
+		Root: "expr",
+	})
+}
+
+/*
+Given: an operator table with prefix, infix and postfix operators.
+When: parses expressions.
+Then: returns the nodes grouped by precedence and associativity.
+*/
+func TestPrattRule(t *testing.T) {
+	cases := map[string]struct {
+		tokens []string
+		expect string
+	}{
+		"left associative":  {[]string{"1", "-", "2", "-", "3"}, "((1 - 2) - 3)"},
+		"right associative": {[]string{"1", "^", "2", "^", "3"}, "(1 ^ (2 ^ 3))"},
+		"precedence":        {[]string{"1", "+", "2", "*", "3", "==", "4"}, "((1 + (2 * 3)) == 4)"},
+		"prefix":            {[]string{"-", "2", "^", "2", "*", "3"}, "((-(2 ^ 2)) * 3)"},
+		"postfix":           {[]string{"-", "2", "!", "+", "(", "1", "+", "2", ")"}, "((-(2!)) + (1 + 2))"},
+	}
+
+	for n, c := range cases {
+		t.Run(n, func(t *testing.T) {
+			// arrange
+			_, prs := setUpPrattParser()
+
+			// act
+			nd, err := prs.Parse(NewReader(c.tokens))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, nd)
+		})
+	}
+}
+
+/*
+Given: an operator table with a non associative operator.
+When: parses an expression with that operator twice.
+Then: returns a parser.SyntaxError at the second operator.
+*/
+func TestPrattRule_with_non_associative_operator(t *testing.T) {
+	// arrange
+	_, prs := setUpPrattParser()
+
+	// act
+	_, err := prs.Parse(NewReader([]string{"1", "==", "2", "==", "3"}))
+
+	// assert
+	var sErr *parser.SyntaxError
+	assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+	assert.ErrorAs(t, err, &sErr)
+	assert.Equal(t, 3, sErr.Position)
+	assert.Equal(t, "expected end of the non associative expression but found ==", sErr.Error())
+}
+
+/*
+Given: a parser made from an operator table.
+When: adds an operator to the table and parses an expression with it.
+Then: returns the node of the new operator.
+*/
+func TestPrattRule_with_runtime_operator(t *testing.T) {
+	// arrange
+	ops, prs := setUpPrattParser()
+
+	// act
+	ops.AddInfix(isOperator("<>"), 15, LeftAssociative, binaryString)
+	nd, err := prs.Parse(NewReader([]string{"1", "+", "2", "<>", "3", "*", "4"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "(1 + (2 <> (3 * 4)))", nd)
+}
@@ -1,5 +1,10 @@
 package ranges
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ByteRange is an alias for a function that take a byte and indicates with a boolean whether the byte is in-range or not.
 type ByteRange func(b byte) bool
 
@@ -39,3 +44,76 @@ func RangeByteOfRange(br ...ByteRange) ByteRange {
 		return false
 	}
 }
+
+// ByteTable is the introspected set of bytes of a ByteRange, indexed by byte.
+type ByteTable [256]bool
+
+// TableOf returns the ByteTable of the range r, by checking every byte.
+func TableOf(r ByteRange) ByteTable {
+	var t ByteTable
+	for i := range t {
+		t[i] = r(byte(i))
+	}
+	return t
+}
+
+// Range returns a ByteRange that indicates whether the byte is in the table or not.
+func (t *ByteTable) Range() ByteRange {
+	return func(b byte) bool {
+		return t[b]
+	}
+}
+
+// Bytes returns the bytes in the table, in increasing order.
+func (t *ByteTable) Bytes() []byte {
+	var bs []byte
+	for i, ok := range t {
+		if ok {
+			bs = append(bs, byte(i))
+		}
+	}
+	return bs
+}
+
+// IsEmpty returns a boolean that indicates whether there is no byte in the table.
+func (t *ByteTable) IsEmpty() bool {
+	for _, ok := range t {
+		if ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the bytes in the table as a list of bounded ranges, such as "'0'-'9', '_', 0x80-0xFF".
+func (t *ByteTable) String() string {
+	var s []string
+
+	for i := 0; i < len(t); i++ {
+		if !t[i] {
+			continue
+		}
+
+		j := i
+		for j+1 < len(t) && t[j+1] {
+			j++
+		}
+
+		if j == i {
+			s = append(s, byteString(byte(i)))
+		} else {
+			s = append(s, byteString(byte(i))+"-"+byteString(byte(j)))
+		}
+
+		i = j
+	}
+
+	return strings.Join(s, ", ")
+}
+
+func byteString(b byte) string {
+	if b > 0x20 && b < 0x7F {
+		return fmt.Sprintf("'%c'", b)
+	}
+	return fmt.Sprintf("0x%02X", b)
+}
@@ -3,10 +3,12 @@ package aldana
 import "github.com/agustin-del-pino/aldana/pkg/aldana/parser"
 
 type defaultReader[T any] struct {
+	expectations
 	tokens   []T
 	token    T
 	length   int
 	position int
+	done     bool
 }
 
 func (r *defaultReader[T]) HasTokens() bool {
@@ -21,12 +23,76 @@ func (r *defaultReader[T]) Next() {
 	if r.position < r.length {
 		r.token = r.tokens[r.position]
 		r.position += 1
+	} else {
+		r.done = true
 	}
 }
 
+func (r *defaultReader[T]) Peek(n int) (T, bool) {
+	i := r.Position() + n
+
+	if r.done || i < 0 || i >= r.length {
+		return *new(T), false
+	}
+
+	return r.tokens[i], true
+}
+
+func (r *defaultReader[T]) Position() int {
+	if r.done {
+		return r.length
+	}
+	return r.position - 1
+}
+
+func (r *defaultReader[T]) Mark() int {
+	return r.Position()
+}
+
+func (r *defaultReader[T]) Reset(m int) {
+	switch {
+	case m >= r.length:
+		r.position, r.done = r.length, true
+		if r.length > 0 {
+			r.token = r.tokens[r.length-1]
+		}
+	case m < 0:
+		r.position, r.done, r.token = 0, false, *new(T)
+	default:
+		r.position, r.done, r.token = m+1, false, r.tokens[m]
+	}
+}
+
+// NewReader returns the default implementation of parser.Reader.
+//
+// # About this implementation
+//   - HasTokens indicates whether there are tokens after the current one.
+//   - Once Next is called at the last token, the Position is the number of tokens and Peek(0) returns false.
 func NewReader[T any](t []T) parser.Reader[T] {
 	return &defaultReader[T]{
 		tokens: t,
 		length: len(t),
 	}
 }
+
+// Try calls f and returns its result. When f fails, the reader r is reset to the position before calling f.
+//
+// # Example
+//
+//	nd, err := Try(r, func() (*Node, error) {
+//		return f("arrow-function", r)
+//	})
+//
+//	if err != nil {
+//		nd, err = f("parenthesized-expression", r)
+//	}
+func Try[T any, Tn any](r parser.Reader[T], f func() (Tn, error)) (Tn, error) {
+	m := r.Mark()
+	nd, err := f()
+
+	if err != nil {
+		r.Reset(m)
+	}
+
+	return nd, err
+}
@@ -0,0 +1,153 @@
+package aldana
+
+import (
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+Given: a n-len token slice.
+When: advances the reader and peeks the tokens.
+Then: returns the tokens after the current one, without advancing the reader.
+*/
+func TestReader_Peek(t *testing.T) {
+	t.Run("no next", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b")
+
+		// act
+		_, ok0 := rdr.Peek(0)
+		tk1, ok1 := rdr.Peek(1)
+
+		// assert
+		assert.False(t, ok0)
+		assert.True(t, ok1)
+		assert.Equal(t, "a", tk1)
+		assert.Equal(t, -1, rdr.Position())
+	})
+
+	t.Run("one next", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b", "c")
+
+		// act
+		rdr.Next()
+		tk0, ok0 := rdr.Peek(0)
+		tk2, ok2 := rdr.Peek(2)
+		_, ok3 := rdr.Peek(3)
+
+		// assert
+		assert.True(t, ok0)
+		assert.Equal(t, "a", tk0)
+		assert.True(t, ok2)
+		assert.Equal(t, "c", tk2)
+		assert.False(t, ok3)
+		assert.Equal(t, "a", rdr.GetToken())
+		assert.Equal(t, 0, rdr.Position())
+	})
+
+	t.Run("over-reaching", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b")
+
+		// act
+		for i := 0; i < 5; i++ {
+			rdr.Next()
+		}
+		_, ok := rdr.Peek(0)
+
+		// assert
+		assert.False(t, ok)
+		assert.Equal(t, 2, rdr.Position())
+		assert.Equal(t, "b", rdr.GetToken())
+	})
+}
+
+/*
+Given: a n-len token slice and a mark.
+When: advances the reader and resets it to the mark.
+Then: the reader is positioned at the mark again.
+*/
+func TestReader_Reset(t *testing.T) {
+	t.Run("middle", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b", "c")
+		rdr.Next()
+		m := rdr.Mark()
+
+		// act
+		rdr.Next()
+		rdr.Next()
+		rdr.Reset(m)
+
+		// assert
+		assert.Equal(t, "a", rdr.GetToken())
+		assert.Equal(t, 0, rdr.Position())
+		assert.True(t, rdr.HasTokens())
+	})
+
+	t.Run("after the last one", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b")
+		rdr.Next()
+		rdr.Next()
+		rdr.Next()
+		m := rdr.Mark()
+
+		// act
+		rdr.Reset(-1)
+		rdr.Reset(m)
+
+		// assert
+		_, ok := rdr.Peek(0)
+		assert.False(t, ok)
+		assert.Equal(t, 2, rdr.Position())
+	})
+}
+
+/*
+Given: a reader and a failing function that advances it.
+When: tries the function.
+Then: returns the error and rewinds the reader.
+*/
+func TestTry(t *testing.T) {
+	t.Run("failure", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b", "c")
+		rdr.Next()
+
+		// act
+		_, err := Try(rdr, func() (string, error) {
+			rdr.Next()
+			rdr.Next()
+			return "", parser.ErrInvalidSyntax
+		})
+
+		// assert
+		assert.ErrorIs(t, err, parser.ErrInvalidSyntax)
+		assert.Equal(t, 0, rdr.Position())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		// arrange
+		rdr := setUpReader("a", "b", "c")
+		rdr.Next()
+
+		// act
+		nd, err := Try(rdr, func() (string, error) {
+			rdr.Next()
+			return rdr.GetToken(), nil
+		})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "b", nd)
+		assert.Equal(t, 1, rdr.Position())
+	})
+}
+
+func setUpReader(t ...string) parser.Reader[string] {
+	return NewReader(t)
+}
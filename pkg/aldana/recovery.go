@@ -0,0 +1,147 @@
+package aldana
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// SyncRule indicates where the parsing continues after the failure of a rule.
+type SyncRule[Tt any] struct {
+	// Until are the predicates of the tokens where the skipping of tokens stops.
+	Until []TokenPredicate[Tt]
+	// Consume indicates whether the token where the skipping stopped is skipped too.
+	Consume bool
+}
+
+// RecoveryOptions contains the options for recover the parser from the failures of the rules.
+type RecoveryOptions[Tt any, Tn any] struct {
+	// Sync are the synchronization rules of the rules that can recover, identified by the rule's name.
+	Sync map[string]SyncRule[Tt]
+	// ErrorNode returns the node that takes the place of the rule n, which failed with err.
+	ErrorNode func(n string, err error) Tn
+	// MaxDiagnostics is the most errors from which the parser recovers, after them the rules fail. When it is zero,
+	// DefaultMaxDiagnostics is used.
+	MaxDiagnostics int
+}
+
+// DefaultMaxDiagnostics is the most errors from which the parser recovers when the MaxDiagnostics is not set.
+const DefaultMaxDiagnostics = 100
+
+// Diagnostics are the errors from which the parser recovered.
+// Use errors.As for get them, errors.Is checks every one of them.
+type Diagnostics []error
+
+func (d Diagnostics) Error() string {
+	s := make([]string, len(d))
+	for i, err := range d {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+func (d Diagnostics) Unwrap() []error {
+	return d
+}
+
+// tryReader is the parser.Reader given to the finder by the combinators that backtrack from the failures of the rules,
+// so the rules do not recover from those failures.
+type tryReader[Tt any] struct {
+	parser.Reader[Tt]
+	// from is the position where a repetition tries the rule, whose failures until there are not recovered. Or -1 when
+	// an alternative is tried, whose rules do not recover at all, neither the rules they find.
+	from int
+}
+
+// trying returns the finder f for the rules that a combinator tries from the position m, or -1 for the alternatives.
+// The reader of a nested combinator is not wrapped again, because its position is the same or a further one.
+func trying[Tt any, Tn any](f ParseRuleFinder[Tt, Tn], m int) ParseRuleFinder[Tt, Tn] {
+	return func(n string, r parser.Reader[Tt]) (Tn, error) {
+		tr, ok := r.(*tryReader[Tt])
+
+		if !ok {
+			return f(n, &tryReader[Tt]{Reader: r, from: m})
+		}
+
+		if m < 0 {
+			tr.from = -1
+		}
+
+		return f(n, tr)
+	}
+}
+
+// committed indicates whether the failure err of a rule, that was tried from the position m, happened after it.
+// It is the position of the parser.SyntaxError of err, otherwise the current one of the reader.
+func committed[Tt any](m int, err error, r parser.Reader[Tt]) bool {
+	var se *parser.SyntaxError
+
+	if errors.As(err, &se) {
+		return se.Position > m
+	}
+
+	return r.Position() > m
+}
+
+// recover records the error err of the rule n and skips the tokens until its SyncRule.
+// When the rule failed where the last recovery ended, only that token is skipped, so the parse always advances.
+// Returns the error node and true, or false when the rule cannot recover.
+func (p *parseRun[Tt, Tn]) recover(n string, err error, r parser.Reader[Tt]) (Tn, bool) {
+	sr, ok := p.ops.Recovery.Sync[n]
+
+	if !ok {
+		return *new(Tn), false
+	}
+
+	max := p.ops.Recovery.MaxDiagnostics
+	if max == 0 {
+		max = DefaultMaxDiagnostics
+	}
+
+	if len(p.diags) >= max {
+		return *new(Tn), false
+	}
+
+	stalled := len(p.diags) != 0 && r.Position() == p.synced
+	p.diags = append(p.diags, err)
+
+	for {
+		t, ok := r.Peek(0)
+
+		if !ok {
+			break
+		}
+
+		if stalled {
+			r.Next()
+			break
+		}
+
+		if isSync(sr.Until, t) {
+			if sr.Consume {
+				r.Next()
+			}
+			break
+		}
+
+		r.Next()
+	}
+
+	p.synced = r.Position()
+
+	if p.ops.Recovery.ErrorNode == nil {
+		return *new(Tn), true
+	}
+
+	return p.ops.Recovery.ErrorNode(n, err), true
+}
+
+func isSync[Tt any](ps []TokenPredicate[Tt], t Tt) bool {
+	for _, p := range ps {
+		if p(t) {
+			return true
+		}
+	}
+	return false
+}
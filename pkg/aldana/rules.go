@@ -0,0 +1,270 @@
+package aldana
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// RuleHandle is a typed reference to a NodeRule. The rules refer to each other by their handles instead of their names,
+// so a misspelled rule does not compile.
+//
+// # Example
+//
+//	decl := NewRuleHandle[*Token, *Node]("declaration")
+//	value := NewRuleHandle[*Token, *Node]("value")
+//
+//	decl.Define(Seq(NewDeclNode, Token(IsLet, "'let'", NewLeaf), value.Parse))
+//	value.Define(Token(IsNumber, "number", NewLeaf))
+//
+//	prs := NewParser(&ParserOptions[*Token, *Node]{
+//		Handles: []*RuleHandle[*Token, *Node]{decl, value},
+//		Root:    decl.Name(),
+//	})
+type RuleHandle[Tt any, Tn any] struct {
+	name string
+	rule NodeRule[Tt, Tn]
+}
+
+// Name returns the name of the rule.
+func (h *RuleHandle[Tt, Tn]) Name() string {
+	return h.name
+}
+
+// Define sets the NodeRule of the handle. The handles can be defined after they are referenced, so the rules can be
+// recursive, but before the parser is made.
+func (h *RuleHandle[Tt, Tn]) Define(r NodeRule[Tt, Tn]) {
+	h.rule = r
+}
+
+// Parse parses the rule of the handle through the finder f, so it is a NodeRule that refers to the handle.
+func (h *RuleHandle[Tt, Tn]) Parse(r parser.Reader[Tt], f ParseRuleFinder[Tt, Tn]) (Tn, error) {
+	return f(h.name, r)
+}
+
+// NewRuleHandle returns a RuleHandle for the rule named n, which is not defined yet.
+func NewRuleHandle[Tt any, Tn any](n string) *RuleHandle[Tt, Tn] {
+	return &RuleHandle[Tt, Tn]{name: n}
+}
+
+// RuleError is an error of a rule found by the validation of the ParserOptions.
+// Use errors.As for get it, and errors.Is for check its cause.
+type RuleError struct {
+	// Rule is the name of the rule.
+	Rule string
+	// Referrer is the name of the rule that refers to the missing Rule. Empty for the other errors.
+	Referrer string
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *RuleError) Error() string {
+	if e.Referrer != "" {
+		return fmt.Sprintf("%s > %s: %s", e.Referrer, e.Rule, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Rule, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// references are the names of the rules referred by every rule.
+type references map[string]map[string]bool
+
+func (rs references) add(from string, to string) {
+	if rs[from] == nil {
+		rs[from] = map[string]bool{}
+	}
+	rs[from][to] = true
+}
+
+// rules returns the rules of the ParseRules and the defined Handles, by name.
+func (ops *ParserOptions[Tt, Tn]) rules() map[string]NodeRule[Tt, Tn] {
+	if len(ops.Handles) == 0 {
+		return ops.ParseRules
+	}
+
+	rs := make(map[string]NodeRule[Tt, Tn], len(ops.ParseRules)+len(ops.Handles))
+
+	for n, r := range ops.ParseRules {
+		rs[n] = r
+	}
+
+	for _, h := range ops.Handles {
+		if h.rule != nil {
+			rs[h.name] = h.rule
+		}
+	}
+
+	return rs
+}
+
+// probe records the references that the rule pr, named n, makes without tokens. Where every referred rule fails.
+// Returns a RuleError with ErrPanickedParserRule when the rule panics.
+func probe[Tt any, Tn any](n string, pr NodeRule[Tt, Tn], refs references) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &RuleError{Rule: n, Err: fmt.Errorf("%w: %v", ErrPanickedParserRule, v)}
+		}
+	}()
+
+	r := NewReader[Tt](nil)
+	r.Next()
+
+	_, _ = pr(r, func(m string, _ parser.Reader[Tt]) (Tn, error) {
+		refs.add(n, m)
+		return *new(Tn), parser.ErrInvalidSyntax
+	})
+
+	return nil
+}
+
+// Validate returns the RuleErrors of the rules of the options, joined. Where the references between the rules are the ones
+// made through the ParseRuleFinder by every rule without tokens, and by parsing the DryRun tokens.
+//
+// # About the errors
+//   - ErrNotFoundRootParserRule when the Root is not declared.
+//   - ErrNotFundParserRule when a handle is not defined, or a referred rule is not declared.
+//   - ErrDuplicatedParserRule when a handle has the name of another rule.
+//   - ErrUnusedParserRule when a rule, other than the Root, is never referred.
+//   - ErrUnreachableParserRule when a rule is referred but not from the Root.
+//   - ErrPanickedParserRule when a rule panics without tokens. Its references are unknown, so the unused and unreachable
+//     rules are not reported then, unless there are DryRun tokens.
+//
+// The references of a rule are found until it reads a token, so the DryRun tokens should reach every rule. Otherwise,
+// the unused and unreachable rules can be false positives. The rules are called without tokens, so they should not have
+// side effects before reading one.
+func (ops *ParserOptions[Tt, Tn]) Validate() error {
+	return errors.Join(ops.validate()...)
+}
+
+// check validates the options. Returns the joined errors when the options are Strict, otherwise writes them to the
+// Warnings, one per line. When neither is set, the options are not validated, so the rules are not called.
+// The ErrPanickedParserRule are always warnings, because a valid rule can read its input without checking for tokens.
+func (ops *ParserOptions[Tt, Tn]) check() error {
+	if !ops.Strict && ops.Warnings == nil {
+		return nil
+	}
+
+	var errs, warns []error
+
+	for _, err := range ops.validate() {
+		if ops.Strict && !errors.Is(err, ErrPanickedParserRule) {
+			errs = append(errs, err)
+		} else {
+			warns = append(warns, err)
+		}
+	}
+
+	if ops.Warnings != nil {
+		for _, err := range warns {
+			fmt.Fprintf(ops.Warnings, "parser: %s\n", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate returns the RuleErrors of the rules of the options, as Validate.
+func (ops *ParserOptions[Tt, Tn]) validate() []error {
+	var errs []error
+
+	rules := ops.rules()
+
+	if _, ok := rules[ops.Root]; !ok {
+		errs = append(errs, &RuleError{Rule: ops.Root, Err: ErrNotFoundRootParserRule})
+	}
+
+	seen := map[string]bool{}
+	for n := range ops.ParseRules {
+		seen[n] = true
+	}
+
+	for _, h := range ops.Handles {
+		switch {
+		case seen[h.name]:
+			errs = append(errs, &RuleError{Rule: h.name, Err: ErrDuplicatedParserRule})
+		case h.rule == nil:
+			errs = append(errs, &RuleError{Rule: h.name, Err: ErrNotFundParserRule})
+		}
+		seen[h.name] = true
+	}
+
+	names := make([]string, 0, len(rules))
+	for n := range rules {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	refs := references{}
+	panicked := false
+
+	for _, n := range names {
+		if err := probe(n, rules[n], refs); err != nil {
+			errs = append(errs, err)
+			panicked = true
+		}
+	}
+
+	for _, tks := range ops.DryRun {
+		run := &parseRun[Tt, Tn]{ops: ops, rules: rules, refs: refs}
+
+		if ops.Memoize {
+			run.memo = map[memoKey]*memoEntry[Tn]{}
+		}
+
+		r := NewReader(tks)
+		r.Next()
+
+		_, _ = run.findRule(ops.Root, r)
+	}
+
+	referred := map[string]bool{}
+
+	for _, n := range names {
+		to := make([]string, 0, len(refs[n]))
+		for m := range refs[n] {
+			to = append(to, m)
+		}
+		sort.Strings(to)
+
+		for _, m := range to {
+			if m != n {
+				referred[m] = true
+			}
+			if _, ok := rules[m]; !ok {
+				errs = append(errs, &RuleError{Rule: m, Referrer: n, Err: ErrNotFundParserRule})
+			}
+		}
+	}
+
+	if panicked && len(ops.DryRun) == 0 {
+		return errs
+	}
+
+	reach := map[string]bool{ops.Root: true}
+
+	for q := []string{ops.Root}; len(q) != 0; q = q[1:] {
+		for m := range refs[q[0]] {
+			if !reach[m] {
+				reach[m] = true
+				q = append(q, m)
+			}
+		}
+	}
+
+	for _, n := range names {
+		switch {
+		case n == ops.Root:
+		case !referred[n]:
+			errs = append(errs, &RuleError{Rule: n, Err: ErrUnusedParserRule})
+		case !reach[n]:
+			errs = append(errs, &RuleError{Rule: n, Err: ErrUnreachableParserRule})
+		}
+	}
+
+	return errs
+}
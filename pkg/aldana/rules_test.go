@@ -0,0 +1,272 @@
+package aldana
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseGroup is a hand-written rule that refers to the rule "list" only after a '(' token.
+func parseGroup(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+	if t, ok := r.Peek(0); !ok || t != "(" {
+		return Token(isDigit, "digit", leafNode)(r, f)
+	}
+
+	r.Next()
+
+	nd, err := f("list", r)
+	if err != nil {
+		return nil, err
+	}
+
+	if t, ok := r.Peek(0); !ok || t != ")" {
+		return nil, parser.NewSyntaxError(t, r.Position(), "')'")
+	}
+
+	r.Next()
+
+	return nd, nil
+}
+
+/*
+Given: a parser made by rule handles that refer to each other.
+When: parses acceptable tokens.
+Then: returns the node made by the rules of the handles.
+*/
+func TestRuleHandle(t *testing.T) {
+	// arrange
+	list := NewRuleHandle[string, *node]("list")
+	item := NewRuleHandle[string, *node]("item")
+
+	list.Define(SepBy(item.Parse, Token(isToken(","), "','", leafNode), listNode))
+	item.Define(Alt(Token(isDigit, "digit", leafNode), Between(Token(isToken("("), "'('", leafNode), list.Parse, Token(isToken(")"), "')'", leafNode))))
+
+	prs := NewParser(&ParserOptions[string, *node]{
+		Handles: []*RuleHandle[string, *node]{list, item},
+		Root:    list.Name(),
+		Strict:  true,
+	})
+
+	// act
+	nd, err := prs.Parse(NewReader([]string{"1", ",", "(", "2", ",", "3", ")"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, nd.Children, 2)
+	assert.Equal(t, "list", nd.Children[1].Type)
+	assert.Len(t, nd.Children[1].Children, 2)
+}
+
+/*
+Given: the options of a parser.
+When: validates them.
+Then: returns the RuleErrors of the missing, duplicated, unused and unreachable rules.
+*/
+func TestParserOptions_Validate(t *testing.T) {
+	item := NewRuleHandle[string, *node]("item")
+	undefined := NewRuleHandle[string, *node]("undefined")
+
+	cases := map[string]struct {
+		ops    *ParserOptions[string, *node]
+		expect []string
+	}{
+		"valid": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list": Many1(Rule[string, *node]("item"), listNode),
+					"item": Token(isDigit, "digit", leafNode),
+				},
+				Root: "list",
+			},
+		},
+		"missing rules": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list": Alt(Rule[string, *node]("item"), Rule[string, *node]("items")),
+				},
+				Handles: []*RuleHandle[string, *node]{undefined},
+				Root:    "program",
+			},
+			expect: []string{
+				"program: the root parser rule was not found",
+				"undefined: the parser rule was not found",
+				"list > item: the parser rule was not found",
+				"list > items: the parser rule was not found",
+				"list: the parser rule is never referred",
+			},
+		},
+		"duplicated handle": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list": Many1(item.Parse, listNode),
+					"item": Token(isDigit, "digit", leafNode),
+				},
+				Handles: []*RuleHandle[string, *node]{item},
+				Root:    "list",
+			},
+			expect: []string{"item: the parser rule is already declared"},
+		},
+		"unused and unreachable rules": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list":  Many1(Rule[string, *node]("item"), listNode),
+					"item":  Token(isDigit, "digit", leafNode),
+					"value": Rule[string, *node]("group"),
+					"group": Rule[string, *node]("value"),
+					"other": Token(isDigit, "digit", leafNode),
+				},
+				Root: "list",
+			},
+			expect: []string{
+				"group: the parser rule is not reachable from the root",
+				"other: the parser rule is never referred",
+				"value: the parser rule is not reachable from the root",
+			},
+		},
+		"panicked rule": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list": Many1(Rule[string, *node]("item"), listNode),
+					"item": func(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+						if !r.HasTokens() {
+							panic("no token")
+						}
+						return Token(isDigit, "digit", leafNode)(r, f)
+					},
+				},
+				Root: "list",
+			},
+			expect: []string{"item: the parser rule panicked without tokens: no token"},
+		},
+		"references found by dry run": {
+			ops: &ParserOptions[string, *node]{
+				ParseRules: map[string]NodeRule[string, *node]{
+					"list":  Many1(Rule[string, *node]("group"), listNode),
+					"group": parseGroup,
+				},
+				Root:   "list",
+				DryRun: [][]string{{"(", "1", ")"}},
+			},
+		},
+	}
+
+	item.Define(Token(isDigit, "digit", leafNode))
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			err := c.ops.Validate()
+
+			// assert
+			if c.expect == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var rErr *RuleError
+			assert.ErrorAs(t, err, &rErr)
+			assert.EqualError(t, err, joinLines(c.expect))
+		})
+	}
+}
+
+func joinLines(s []string) string {
+	errs := make([]error, len(s))
+	for i, l := range s {
+		errs[i] = errors.New(l)
+	}
+	return errors.Join(errs...).Error()
+}
+
+/*
+Given: strict options with a rule referred only after some tokens.
+When: parses acceptable tokens, with and without the DryRun tokens that reach the rule.
+Then: fails with ErrUnusedParserRule without them, and returns the node with them.
+*/
+func TestParserOptions_Strict(t *testing.T) {
+	// arrange
+	ops := &ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"program": Many1(Rule[string, *node]("group"), listNode),
+			"group":   parseGroup,
+			"list":    Many1(Rule[string, *node]("group"), listNode),
+		},
+		Root:   "program",
+		Strict: true,
+	}
+
+	// act
+	_, err := NewParser(ops).Parse(NewReader([]string{"1", "(", "2", "3", ")"}))
+
+	ops.DryRun = [][]string{{"(", "1", ")"}}
+	nd, dErr := NewParser(ops).Parse(NewReader([]string{"1", "(", "2", "3", ")"}))
+
+	// assert
+	assert.ErrorIs(t, err, ErrUnusedParserRule)
+	assert.EqualError(t, err, "list: the parser rule is never referred")
+	assert.NoError(t, dErr)
+	assert.Len(t, nd.Children, 2)
+}
+
+/*
+Given: strict options with a valid rule that indexes its input unconditionally, and a Warnings writer.
+When: makes the parser and parses acceptable tokens.
+Then: writes the panic of the rule to the Warnings, and returns the node.
+*/
+func TestParserOptions_Strict_with_panicked_rule(t *testing.T) {
+	// arrange
+	var w bytes.Buffer
+	ops := &ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"pair": Seq(listNode, Rule[string, *node]("item"), Rule[string, *node]("item")),
+			"item": func(r parser.Reader[string], f ParseRuleFinder[string, *node]) (*node, error) {
+				if t, _ := r.Peek(0); t[0] == '(' {
+					return f("group", r)
+				}
+				return Token(isDigit, "digit", leafNode)(r, f)
+			},
+			"group": Seq(listNode, Token(isToken("("), "'('", leafNode), Token(isDigit, "digit", leafNode), Token(isToken(")"), "')'", leafNode)),
+		},
+		Root:     "pair",
+		Strict:   true,
+		Warnings: &w,
+	}
+
+	// act
+	nd, err := NewParser(ops).Parse(NewReader([]string{"1", "(", "2", ")"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, nd.Children, 2)
+	assert.Contains(t, w.String(), "parser: item: the parser rule panicked without tokens")
+}
+
+/*
+Given: non strict options with an unused rule and a Warnings writer.
+When: makes the parser and parses acceptable tokens.
+Then: writes the errors of the validation to the Warnings, and returns the node.
+*/
+func TestParserOptions_Warnings(t *testing.T) {
+	// arrange
+	var w bytes.Buffer
+	ops := &ParserOptions[string, *node]{
+		ParseRules: map[string]NodeRule[string, *node]{
+			"list":  Many1(Rule[string, *node]("item"), listNode),
+			"item":  Token(isDigit, "digit", leafNode),
+			"other": Token(isDigit, "digit", leafNode),
+		},
+		Root:     "list",
+		Warnings: &w,
+	}
+
+	// act
+	nd, err := NewParser(ops).Parse(NewReader([]string{"1", "2"}))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, nd.Children, 2)
+	assert.Equal(t, "parser: other: the parser rule is never referred\n", w.String())
+}
@@ -0,0 +1,207 @@
+package aldana
+
+import (
+	"context"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+)
+
+// StreamReader is a parser.Reader of the tokens that a lexer goroutine emits while the parser reads them.
+// The reader waits for the tokens that it needs, and retains the read ones, so Mark and Reset work as usual.
+// The expected labels are recorded as the default reader does, so the errors are the same.
+type StreamReader[T any] struct {
+	expectations
+	ctx    context.Context
+	cancel context.CancelFunc
+	ch     <-chan T
+	// closed indicates whether the channel is closed, so the tokens are all of them.
+	closed bool
+	// err is the error of the lexer, or of the context when it was done before the lexer.
+	err error
+	// lexErr is written by the lexer goroutine before closing the channel.
+	lexErr error
+
+	tokens   []T
+	token    T
+	position int
+	done     bool
+}
+
+// fill waits until the token at the index i is emitted, and returns a boolean that indicates whether it exists.
+func (r *StreamReader[T]) fill(i int) bool {
+	for !r.closed && len(r.tokens) <= i {
+		select {
+		case t, ok := <-r.ch:
+			if !ok {
+				r.closed = true
+				r.err = r.lexErr
+				break
+			}
+			r.tokens = append(r.tokens, t)
+		case <-r.ctx.Done():
+			r.closed = true
+			r.err = r.ctx.Err()
+		}
+	}
+
+	return i >= 0 && i < len(r.tokens)
+}
+
+func (r *StreamReader[T]) HasTokens() bool {
+	return r.fill(r.position)
+}
+
+func (r *StreamReader[T]) GetToken() T {
+	return r.token
+}
+
+func (r *StreamReader[T]) Next() {
+	if r.fill(r.position) {
+		r.token = r.tokens[r.position]
+		r.position += 1
+	} else {
+		r.done = true
+	}
+}
+
+func (r *StreamReader[T]) Peek(n int) (T, bool) {
+	i := r.Position() + n
+
+	if r.done || !r.fill(i) {
+		return *new(T), false
+	}
+
+	return r.tokens[i], true
+}
+
+func (r *StreamReader[T]) Position() int {
+	if r.done {
+		return len(r.tokens)
+	}
+	return r.position - 1
+}
+
+func (r *StreamReader[T]) Mark() int {
+	return r.Position()
+}
+
+func (r *StreamReader[T]) Reset(m int) {
+	switch {
+	case m >= 0 && r.fill(m):
+		r.position, r.done, r.token = m+1, false, r.tokens[m]
+	case m >= 0:
+		r.position, r.done = len(r.tokens), true
+		if len(r.tokens) > 0 {
+			r.token = r.tokens[len(r.tokens)-1]
+		}
+	default:
+		r.position, r.done, r.token = 0, false, *new(T)
+	}
+}
+
+// Err returns the error of the lexer, or of the context when it was done first. Nil while the tokens are not all read.
+func (r *StreamReader[T]) Err() error {
+	return r.err
+}
+
+// Close stops the lexer goroutine. The read tokens can still be read.
+func (r *StreamReader[T]) Close() {
+	r.cancel()
+}
+
+// streamerOf returns the lexer l as a lexer.Streamer. When it is not one, its tokens are emitted after the tokenization.
+func streamerOf[T any](l lexer.Lexer[T]) lexer.Streamer[T] {
+	if s, ok := l.(lexer.Streamer[T]); ok {
+		return s
+	}
+
+	return tokenizeStreamer[T]{l}
+}
+
+// tokenizeStreamer implements lexer.Streamer by emitting the tokens of a lexer.Lexer.
+type tokenizeStreamer[T any] struct {
+	l lexer.Lexer[T]
+}
+
+func (s tokenizeStreamer[T]) Stream(ctx context.Context, c lexer.Cursor, emit func(t T) error) error {
+	tks, err := s.l.Tokenize(c)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tks {
+		if err := emit(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewStreamReader returns a StreamReader of the tokens of the lexer l, which reads the cursor c in a new goroutine.
+// Where size is the number of tokens that the lexer can emit before the parser reads them.
+// The goroutine stops at the end of the bytes, or when ctx is done or the reader is closed.
+//
+// # Example
+//
+//	r := NewStreamReader(ctx, NewLexer(lexOps), NewCursor(src), 256)
+//	defer r.Close()
+//
+//	nd, err := prs.Parse(r)
+//	if lErr := r.Err(); lErr != nil {
+//		err = lErr
+//	}
+func NewStreamReader[T any](ctx context.Context, l lexer.Lexer[T], c lexer.Cursor, size int) *StreamReader[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan T, size)
+
+	r := &StreamReader[T]{ctx: ctx, cancel: cancel, ch: ch}
+
+	go func() {
+		defer close(ch)
+
+		r.lexErr = streamerOf(l).Stream(ctx, c, func(t T) error {
+			select {
+			case ch <- t:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return r
+}
+
+// ParseStream parses the tokens of the lexer l, read from the cursor c, with the parser p, while the lexer reads them.
+// Where size is the number of tokens that the lexer can emit before the parser reads them.
+//
+// # About the errors
+//   - When the lexer fails, its error is returned, because the parser only found the end of the tokens before it.
+//   - When the parser fails, the lexer is stopped and the error of the parser is returned.
+//   - When ctx is done, both are stopped and the error of ctx is returned.
+//
+// # Example
+//
+//	nd, err := ParseStream(ctx, NewParser(prsOps), NewLexer(lexOps), NewCursor(src), 256)
+func ParseStream[Tt any, Tn any](ctx context.Context, p parser.Parser[Tt, Tn], l lexer.Lexer[Tt], c lexer.Cursor, size int) (Tn, error) {
+	r := NewStreamReader(ctx, l, c, size)
+	defer r.Close()
+
+	nd, err := p.Parse(r)
+
+	if rErr := r.Err(); rErr != nil {
+		return *new(Tn), rErr
+	}
+
+	if err != nil {
+		return nd, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return *new(Tn), err
+	}
+
+	return nd, nil
+}
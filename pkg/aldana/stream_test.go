@@ -0,0 +1,247 @@
+package aldana
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// endlessLexer implements lexer.Streamer by emitting numbers until ctx is done, where every zero-th is "0".
+type endlessLexer struct {
+	zero    int
+	stopped chan struct{}
+}
+
+func (l *endlessLexer) Tokenize(lexer.Cursor) ([]*token, error) {
+	return nil, nil
+}
+
+func (l *endlessLexer) Stream(ctx context.Context, _ lexer.Cursor, emit func(t *token) error) error {
+	defer close(l.stopped)
+
+	for i := 1; ; i++ {
+		v := strconv.Itoa(i)
+		if l.zero != 0 && i%l.zero == 0 {
+			v = "0"
+		}
+
+		if err := emit(&token{Type: "num", Value: []byte(v)}); err != nil {
+			return err
+		}
+	}
+}
+
+// tokenizeOnly hides the Stream of a lexer.
+type tokenizeOnly struct {
+	lexer.Lexer[*token]
+}
+
+func setUpStreamParser(p TokenPredicate[*token]) parser.Parser[*token, []string] {
+	return NewParser(&ParserOptions[*token, []string]{
+		ParseRules: map[string]NodeRule[*token, []string]{
+			"list": Many1(Token(p, "number", func(t *token) []string {
+				return []string{string(t.Value)}
+			}), func(nds [][]string) []string {
+				var s []string
+				for _, n := range nds {
+					s = append(s, n...)
+				}
+				return s
+			}),
+		},
+		Root: "list",
+	})
+}
+
+func isNonZero(t *token) bool {
+	return t.Type == "num" && string(t.Value) != "0"
+}
+
+/*
+Given: a stream reader of a lexer.
+When: reads, marks and resets the tokens.
+Then: waits for the tokens, and retains the read ones.
+*/
+func TestStreamReader(t *testing.T) {
+	// arrange
+	r := NewStreamReader(context.Background(), setUpLexer(mockLexerRule()), mockCursor([]byte("1 2 3")), 1)
+	defer r.Close()
+
+	// act
+	r.Next()
+	m := r.Mark()
+	r.Next()
+	r.Next()
+	r.Reset(m)
+	t0 := r.GetToken()
+	t2, ok2 := r.Peek(2)
+	_, ok3 := r.Peek(3)
+	r.Next()
+	r.Next()
+	has := r.HasTokens()
+	r.Next()
+
+	// assert
+	assert.Equal(t, "1", string(t0.Value))
+	assert.True(t, ok2)
+	assert.Equal(t, "3", string(t2.Value))
+	assert.False(t, ok3)
+	assert.False(t, has)
+	assert.Equal(t, 3, r.Position())
+	assert.NoError(t, r.Err())
+}
+
+/*
+Given: a parser and a lexer.
+When: parses the stream of the lexer.
+Then: returns the node, or the error of the lexer.
+*/
+func TestParseStream(t *testing.T) {
+	cases := map[string]struct {
+		lex    lexer.Lexer[*token]
+		src    string
+		expect []string
+		err    error
+	}{
+		"streamer":             {lex: setUpLexer(mockLexerRule()), src: "1 22 333", expect: []string{"1", "22", "333"}},
+		"non streamer":         {lex: tokenizeOnly{setUpLexer(mockLexerRule())}, src: "1 22 333", expect: []string{"1", "22", "333"}},
+		"lexer error":          {lex: setUpLexer(mockLexerRule()), src: "1 22 A", err: lexer.ErrUnexpectedChar},
+		"non streamer error":   {lex: tokenizeOnly{setUpLexer(mockLexerRule())}, src: "A", err: lexer.ErrUnexpectedChar},
+		"parser error":         {lex: setUpLexer(mockLexerRule()), src: "1 0 2", err: parser.ErrUnhandledToken},
+		"parser error at once": {lex: setUpLexer(mockLexerRule()), src: "0 1", err: parser.ErrInvalidSyntax},
+	}
+
+	prs := setUpStreamParser(isNonZero)
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			nd, err := ParseStream(context.Background(), prs, c.lex, mockCursor([]byte(c.src)), 2)
+
+			// assert
+			if c.err != nil {
+				assert.ErrorIs(t, err, c.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, nd)
+		})
+	}
+}
+
+/*
+Given: a parser of alternatives, and the tokens of a lexer that none of them accepts.
+When: parses the tokens by a stream reader and by the default reader.
+Then: returns the same error, with the labels of every alternative.
+*/
+func TestParseStream_with_expected_labels(t *testing.T) {
+	// arrange
+	isNum := func(v string) TokenPredicate[*token] {
+		return func(t *token) bool {
+			return string(t.Value) == v
+		}
+	}
+	leaf := func(t *token) []string {
+		return []string{string(t.Value)}
+	}
+	prs := NewParser(&ParserOptions[*token, []string]{
+		ParseRules: map[string]NodeRule[*token, []string]{
+			"digit": Alt(Token(isNum("1"), "'1'", leaf), Token(isNum("2"), "'2'", leaf)),
+		},
+		Root: "digit",
+	})
+	lex := setUpLexer(mockLexerRule())
+	tks, err := lex.Tokenize(mockCursor([]byte("3")))
+	assert.NoError(t, err)
+
+	// act
+	_, sErr := ParseStream[*token, []string](context.Background(), prs, lex, mockCursor([]byte("3")), 1)
+	_, dErr := prs.Parse(NewReader(tks))
+
+	// assert
+	var se *parser.SyntaxError
+	assert.ErrorAs(t, sErr, &se)
+	assert.Equal(t, []string{"'1'", "'2'"}, se.Expected)
+	assert.EqualError(t, sErr, dErr.Error())
+}
+
+/*
+Given: a parser and a lexer with fallible lex-rules.
+When: parses the stream of the lexer, with an omitted token and with a failed one.
+Then: omits the token, or returns the error of the lex-rule.
+*/
+func TestParseStream_with_fallible_rules(t *testing.T) {
+	prs := setUpStreamParser(isNonZero)
+
+	t.Run("dropped token", func(t *testing.T) {
+		// act
+		nd, err := ParseStream[*token, []string](context.Background(), prs, setUpFallibleLexer(), mockCursor([]byte("12 #34 56")), 1)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"12", "56"}, nd)
+	})
+
+	t.Run("failed token", func(t *testing.T) {
+		// act
+		_, err := ParseStream[*token, []string](context.Background(), prs, setUpFallibleLexer(), mockCursor([]byte("12 \"34")), 1)
+
+		// assert
+		assert.ErrorIs(t, err, errUnterminated)
+	})
+}
+
+/*
+Given: a parser and an endless lexer.
+When: the parser fails, or the context is canceled while parsing the stream.
+Then: returns the error and stops the lexer.
+*/
+func TestParseStream_stops_the_lexer(t *testing.T) {
+	t.Run("parser error", func(t *testing.T) {
+		// arrange
+		l := &endlessLexer{zero: 100, stopped: make(chan struct{})}
+
+		// act
+		nd, err := ParseStream(context.Background(), setUpStreamParser(isNonZero), lexer.Lexer[*token](l), nil, 8)
+
+		// assert
+		assert.ErrorIs(t, err, parser.ErrUnhandledToken)
+		assert.Len(t, nd, 99)
+		assertStopped(t, l.stopped)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		// arrange
+		l := &endlessLexer{stopped: make(chan struct{})}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		prs := setUpStreamParser(func(t *token) bool {
+			if strings.HasSuffix(string(t.Value), "000") {
+				cancel()
+			}
+			return true
+		})
+
+		// act
+		_, err := ParseStream(ctx, prs, lexer.Lexer[*token](l), nil, 8)
+
+		// assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assertStopped(t, l.stopped)
+	})
+}
+
+func assertStopped(t *testing.T, s chan struct{}) {
+	select {
+	case <-s:
+	case <-time.After(time.Second):
+		t.Error("the lexer was not stopped")
+	}
+}
@@ -0,0 +1,141 @@
+package token
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+)
+
+// Span is the range of the input where a token was read.
+type Span = lexer.Span
+
+// Token is a generic token. Where K is the type for the kinds of the tokens.
+type Token[K comparable] struct {
+	// Kind is the kind of the token.
+	Kind K
+	// Span is the range of the input where the token was read.
+	Span
+	// Raw are the bytes of the input that were read.
+	Raw []byte
+	// Value is the literal value of the token, such as a parsed number.
+	Value any
+}
+
+// String returns the kind and the raw bytes of the token.
+func (t *Token[K]) String() string {
+	return fmt.Sprintf("%v %q", t.Kind, t.Raw)
+}
+
+// Text returns the raw bytes of the token as string.
+func (t *Token[K]) Text() string {
+	return string(t.Raw)
+}
+
+// GetSpan returns the Span where the token was read. Implements lexer.Spanned.
+func (t *Token[K]) GetSpan() Span {
+	return t.Span
+}
+
+// Is returns a boolean that indicates whether the token is of kind k.
+func (t *Token[K]) Is(k K) bool {
+	return t.Kind == k
+}
+
+// IsRaw returns a boolean that indicates whether the raw bytes of the token are equal to s.
+func (t *Token[K]) IsRaw(s string) bool {
+	return bytes.Equal(t.Raw, []byte(s))
+}
+
+// IsValue returns a boolean that indicates whether the literal value of the token is equal to v.
+func (t *Token[K]) IsValue(v any) bool {
+	return reflect.DeepEqual(t.Value, v)
+}
+
+// IsKind returns a predicate that indicates whether a token is of any of the kinds k.
+func IsKind[K comparable](k ...K) func(t *Token[K]) bool {
+	return func(t *Token[K]) bool {
+		for _, v := range k {
+			if t.Kind == v {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsRaw returns a predicate that indicates whether the raw bytes of a token are equal to any of s.
+func IsRaw[K comparable](s ...string) func(t *Token[K]) bool {
+	return func(t *Token[K]) bool {
+		for _, v := range s {
+			if t.IsRaw(v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// recordCursor implements lexer.Cursor by recording every char that is advanced.
+type recordCursor struct {
+	lexer.Cursor
+	raw []byte
+}
+
+func (c *recordCursor) Next() {
+	if c.Cursor.HasChar() {
+		c.raw = append(c.raw, c.Cursor.GetChar())
+	}
+	c.Cursor.Next()
+}
+
+// Read returns a new token of kind k, with the bytes that f advances and their Span.
+//
+// # Example
+//
+//	t := Read(c, Str, func(c lexer.Cursor) {
+//		c.Next()
+//		for c.HasChar() && c.GetChar() != '"' {
+//			c.Next()
+//		}
+//		c.Next()
+//	})
+func Read[K comparable](c lexer.Cursor, k K, f func(c lexer.Cursor)) *Token[K] {
+	rc := &recordCursor{Cursor: c}
+	t := &Token[K]{Kind: k}
+
+	t.Start = lexer.PositionOf(c)
+	f(rc)
+	t.End = lexer.PositionOf(c)
+	t.Raw = rc.raw
+
+	return t
+}
+
+// NewTokenRule returns an aldana.TokenRule that reads a token of kind k, while the chars are in the range.
+func NewTokenRule[K comparable](k K) aldana.TokenRule[*Token[K]] {
+	return func(c lexer.Cursor, r ranges.ByteRange) *Token[K] {
+		return Read(c, k, func(c lexer.Cursor) {
+			for c.HasChar() && r(c.GetChar()) {
+				c.Next()
+			}
+		})
+	}
+}
+
+// NewLexicalRule returns an aldana.LexicalRule that reads a token of kind k, while the chars are in the range r.
+//
+// # Example
+//
+//	l := aldana.NewLexer(&aldana.LexerOptions[*Token[Kind]]{
+//		Ignore: aldana.IgnoreWhiteSpaces(),
+//		LexRules: []aldana.LexicalRule[*Token[Kind]]{
+//			NewLexicalRule(Num, ranges.ByteBounded(0x30, 0x39)),
+//		},
+//	})
+func NewLexicalRule[K comparable](k K, r ranges.ByteRange) aldana.LexicalRule[*Token[K]] {
+	return aldana.NewLexicalRule(r, NewTokenRule(k))
+}
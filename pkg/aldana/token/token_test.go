@@ -0,0 +1,65 @@
+package token
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agustin-del-pino/aldana/pkg/aldana"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/lexer"
+	"github.com/agustin-del-pino/aldana/pkg/aldana/ranges"
+	"github.com/stretchr/testify/assert"
+)
+
+type kind int
+
+const (
+	num kind = iota
+	word
+)
+
+/*
+Given: a lexer of generic tokens.
+When: tokenizes the chars.
+Then: returns tokens with their kind, raw bytes and span.
+*/
+func TestNewLexicalRule(t *testing.T) {
+	// arrange
+	lex := aldana.NewLexer(&aldana.LexerOptions[*Token[kind]]{
+		Ignore: aldana.IgnoreWhiteSpaces(),
+		LexRules: []aldana.LexicalRule[*Token[kind]]{
+			NewLexicalRule(num, ranges.ByteBounded(0x30, 0x39)),
+			NewLexicalRule(word, ranges.ByteBounded(0x61, 0x7A)),
+		},
+	})
+
+	// act
+	tks, err := lex.Tokenize(aldana.NewCursor([]byte("let 42")))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, tks, 2)
+	assert.True(t, tks[0].Is(word))
+	assert.True(t, tks[0].IsRaw("let"))
+	assert.Equal(t, Span{Start: lexer.Position{Column: 1}, End: lexer.Position{Column: 4}}, tks[0].Span)
+	assert.True(t, IsKind(num)(tks[1]))
+	assert.True(t, IsRaw[kind]("42")(tks[1]))
+	assert.Equal(t, Span{Start: lexer.Position{Column: 5}, End: lexer.Position{Column: 7}}, tks[1].GetSpan())
+}
+
+/*
+Given: a generic token.
+When: gets the token error.
+Then: returns an error with the token position, that wraps the original error.
+*/
+func TestToken_with_GetTokenError(t *testing.T) {
+	// arrange
+	er := errors.New("unexpected")
+	tk := &Token[kind]{Kind: word, Raw: []byte("foo"), Span: Span{Start: lexer.Position{Line: 2, Column: 7}}}
+
+	// act
+	err := aldana.GetTokenError(er, tk)
+
+	// assert
+	assert.ErrorIs(t, err, er)
+	assert.Equal(t, "unexpected 1 \"foo\" at: line 2 column 7", err.Error())
+}